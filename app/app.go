@@ -0,0 +1,120 @@
+// Package app runs a service's long-lived components (HTTP and gRPC servers,
+// NATS connections, database pools) and shuts them down in order.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+)
+
+// DefaultStopTimeout bounds each component's Stop unless overridden with WithStopTimeout.
+const DefaultStopTimeout = 15 * time.Second
+
+// Component is a part of a service managed by Run.
+type Component interface {
+	// Name identifies the component in logs and errors.
+	Name() string
+
+	// Start runs the component. It may block until Stop is called (servers)
+	// or return nil immediately (already-connected clients), and must return
+	// once Stop has been called. A non-nil error makes Run shut everything down.
+	Start(ctx context.Context) error
+
+	// Stop gracefully stops the component, giving up when ctx is done.
+	Stop(ctx context.Context) error
+}
+
+// Run starts every component concurrently and blocks until ctx is cancelled,
+// SIGINT or SIGTERM is received, or a component fails to start. It then stops
+// the components in reverse order, so pass dependencies first:
+//
+//	err := app.Run(ctx,
+//		app.Postgres(pool),            // closed last
+//		app.NATS(nc),                  // drained after servers stop
+//		app.GRPCServer(srv, ":9090"),
+//		app.HTTPServer(httpSrv),       // stops accepting first
+//	)
+//
+// Each Stop is bounded by DefaultStopTimeout (see WithStopTimeout). The
+// returned error joins every start and stop failure; it is nil on a clean shutdown.
+func Run(ctx context.Context, components ...Component) error {
+	log := logger.From(ctx)
+
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		startErr []error
+	)
+	for _, c := range components {
+		wg.Add(1)
+		go func(c Component) {
+			defer wg.Done()
+			log.Info().Str("component", c.Name()).Msg("starting component")
+			if err := c.Start(runCtx); err != nil {
+				log.Error().Err(err).Str("component", c.Name()).Msg("component failed")
+				mu.Lock()
+				startErr = append(startErr, fmt.Errorf("%s: %w", c.Name(), err))
+				mu.Unlock()
+				cancel()
+			}
+		}(c)
+	}
+
+	<-runCtx.Done()
+	if ctx.Err() != nil {
+		log.Info().Msg("shutting down")
+	}
+
+	var stopErr []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		timeout := DefaultStopTimeout
+		if t, ok := c.(interface{ StopTimeout() time.Duration }); ok {
+			timeout = t.StopTimeout()
+		}
+
+		// Stop must run even though ctx is already cancelled.
+		stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		start := time.Now()
+		err := c.Stop(stopCtx)
+		stopCancel()
+		if err != nil {
+			log.Error().Err(err).Str("component", c.Name()).Msg("component stop failed")
+			stopErr = append(stopErr, fmt.Errorf("stopping %s: %w", c.Name(), err))
+			continue
+		}
+		log.Info().Str("component", c.Name()).Dur("duration", time.Since(start)).Msg("component stopped")
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return errors.Join(append(startErr, stopErr...)...)
+}
+
+type timeoutComponent struct {
+	Component
+	timeout time.Duration
+}
+
+func (c timeoutComponent) StopTimeout() time.Duration { return c.timeout }
+
+// WithStopTimeout overrides DefaultStopTimeout for c.
+func WithStopTimeout(c Component, timeout time.Duration) Component {
+	return timeoutComponent{Component: c, timeout: timeout}
+}
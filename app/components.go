@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+)
+
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// Func builds a Component from start and stop functions; either may be nil.
+func Func(name string, start, stop func(ctx context.Context) error) Component {
+	return funcComponent{name: name, start: start, stop: stop}
+}
+
+func (c funcComponent) Name() string { return c.name }
+
+func (c funcComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c funcComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+// HTTPServer serves srv with ListenAndServe (or ListenAndServeTLS when
+// srv.TLSConfig has certificates) and stops it with Shutdown, which stops
+// accepting connections and drains in-flight requests, falling back to Close
+// when the stop timeout expires.
+func HTTPServer(srv *http.Server) Component {
+	return Func("http",
+		func(context.Context) error {
+			var err error
+			if srv.TLSConfig != nil && (len(srv.TLSConfig.Certificates) > 0 || srv.TLSConfig.GetCertificate != nil) {
+				err = srv.ListenAndServeTLS("", "")
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		},
+		func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if ctx.Err() != nil {
+				srv.Close()
+			}
+			return err
+		},
+	)
+}
+
+// GRPCServer listens on addr and serves srv. Stop calls GracefulStop and
+// falls back to Stop, cancelling in-flight RPCs, when the stop timeout expires.
+func GRPCServer(srv *grpc.Server, addr string) Component {
+	return Func("grpc",
+		func(context.Context) error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			// Serve fails with ErrServerStopped if Stop ran before it started,
+			// e.g. because another component failed to start.
+			if err := srv.Serve(lis); !errors.Is(err, grpc.ErrServerStopped) {
+				return err
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	)
+}
+
+// NATS drains nc on Stop so in-flight messages are processed and pending
+// publishes flushed, closing it outright when the stop timeout expires.
+func NATS(nc *nats.Conn) Component {
+	return Func("nats", nil, func(ctx context.Context) error {
+		if nc.IsClosed() {
+			return nil
+		}
+		if err := nc.Drain(); err != nil {
+			nc.Close()
+			return err
+		}
+
+		ticker := time.NewTicker(25 * time.Millisecond)
+		defer ticker.Stop()
+		for !nc.IsClosed() {
+			select {
+			case <-ctx.Done():
+				nc.Close()
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		return nil
+	})
+}
+
+// Postgres closes pool on Stop, waiting for acquired connections to be released.
+func Postgres(pool *pgxpool.Pool) Component {
+	return Func("postgres", nil, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			pool.Close()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
@@ -8,19 +8,36 @@ import (
 )
 
 // Common holds common configuration fields shared across services.
+// It can be embedded in a service config loaded with Load.
 type Common struct {
-	ServiceName string
-	LogLevel    string
-	Environment string
+	ServiceName string      `env:"SERVICE_NAME" default:"unknown" desc:"Service identifier used in logs and telemetry"`
+	LogLevel    string      `env:"LOG_LEVEL" default:"info" validate:"oneof=trace debug info warn error fatal panic" desc:"Minimum log level"`
+	Environment Environment `env:"ENVIRONMENT" default:"development" desc:"Deployment environment (development, staging, production, test)"`
 }
 
 // LoadCommon loads common configuration from environment variables.
+// Unknown log levels fall back to "info" and unknown environments to
+// Production (see CurrentEnvironment); use LoadCommonStrict to reject them.
 func LoadCommon() Common {
-	return Common{
+	c := Common{
 		ServiceName: GetEnv("SERVICE_NAME", "unknown"),
 		LogLevel:    GetEnv("LOG_LEVEL", "info"),
-		Environment: GetEnv("ENVIRONMENT", "development"),
+		Environment: CurrentEnvironment(),
 	}
+	if err := Validate(&c); err != nil {
+		c.LogLevel = "info"
+	}
+	return c
+}
+
+// LoadCommonStrict is like LoadCommon but fails if any variable is malformed.
+// The returned *LoadError lists every offending key and value.
+func LoadCommonStrict() (Common, error) {
+	var c Common
+	if err := Load(&c); err != nil {
+		return Common{}, err
+	}
+	return c, nil
 }
 
 // GetEnv retrieves an environment variable or returns a default value.
@@ -32,42 +49,93 @@ func GetEnv(key, defaultValue string) string {
 }
 
 // GetEnvInt retrieves an environment variable as an integer or returns a default.
+// Malformed values fall back to the default; use LookupInt to detect them.
 func GetEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
-		}
+	if i, err := LookupInt(key, defaultValue); err == nil {
+		return i
 	}
 	return defaultValue
 }
 
 // GetEnvInt32 retrieves an environment variable as an int32 or returns a default.
+// Malformed values fall back to the default; use LookupInt32 to detect them.
 func GetEnvInt32(key string, defaultValue int32) int32 {
-	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
-			return int32(i)
-		}
+	if i, err := LookupInt32(key, defaultValue); err == nil {
+		return i
 	}
 	return defaultValue
 }
 
 // GetEnvDuration retrieves an environment variable as a duration or returns a default.
+// Malformed values fall back to the default; use LookupDuration to detect them.
 func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if d, err := time.ParseDuration(value); err == nil {
-			return d
-		}
+	if d, err := LookupDuration(key, defaultValue); err == nil {
+		return d
 	}
 	return defaultValue
 }
 
 // GetEnvBool retrieves an environment variable as a boolean or returns a default.
+// Malformed values fall back to the default; use LookupBool to detect them.
 func GetEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if b, err := strconv.ParseBool(value); err == nil {
-			return b
-		}
+	if b, err := LookupBool(key, defaultValue); err == nil {
+		return b
 	}
 	return defaultValue
 }
 
+// LookupInt retrieves an environment variable as an integer.
+// It returns the default if the variable is unset and a *FieldError if it is malformed.
+func LookupInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue, &FieldError{Key: key, Value: value, Err: err}
+	}
+	return i, nil
+}
+
+// LookupInt32 retrieves an environment variable as an int32.
+// It returns the default if the variable is unset and a *FieldError if it is malformed.
+func LookupInt32(key string, defaultValue int32) (int32, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return defaultValue, &FieldError{Key: key, Value: value, Err: err}
+	}
+	return int32(i), nil
+}
+
+// LookupDuration retrieves an environment variable as a duration.
+// It returns the default if the variable is unset and a *FieldError if it is malformed.
+func LookupDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, &FieldError{Key: key, Value: value, Err: err}
+	}
+	return d, nil
+}
+
+// LookupBool retrieves an environment variable as a boolean.
+// It returns the default if the variable is unset and a *FieldError if it is malformed.
+func LookupBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, &FieldError{Key: key, Value: value, Err: err}
+	}
+	return b, nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Variable documents a single environment variable accepted by a config struct.
+type Variable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Rules       string `json:"rules,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Description lists every variable a config struct accepts, in field order.
+type Description []Variable
+
+// Describe documents every tagged field of the struct pointed to by dst,
+// using the env, default, required, validate and desc tags:
+//
+//	Port int `env:"PORT" default:"8080" validate:"min=1" desc:"HTTP listen port"`
+//
+// Wire it to a flag so operators can see what a service accepts:
+//
+//	if *printHelp {
+//		desc, _ := config.Describe(&cfg)
+//		desc.Write(os.Stdout, "markdown")
+//		os.Exit(0)
+//	}
+//
+// dst is not modified. Defaults of Secret fields are reported as "[REDACTED]".
+func Describe(dst any) (Description, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("describing config: destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+
+	scratch := reflect.New(rv.Elem().Type())
+
+	var out Description
+	err := walkFields(scratch.Elem(), "", true, func(f field) {
+		v := Variable{
+			Name:        f.key,
+			Type:        typeName(f.structField.Type),
+			Default:     f.defaultValue,
+			Required:    f.required,
+			Rules:       f.structField.Tag.Get("validate"),
+			Description: f.structField.Tag.Get("desc"),
+		}
+		if v.Default != "" && isSecret(f.structField.Type) {
+			v.Default = Redacted
+		}
+		out = append(out, v)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing config: %w", err)
+	}
+	return out, nil
+}
+
+// Write renders the description as "table" (aligned plain text), "markdown" or "json".
+func (d Description) Write(w io.Writer, format string) error {
+	switch format {
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tREQUIRED\tRULES\tDESCRIPTION")
+		for _, v := range d {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n", v.Name, v.Type, v.Default, v.Required, v.Rules, v.Description)
+		}
+		return tw.Flush()
+	case "markdown":
+		var b strings.Builder
+		b.WriteString("| Variable | Type | Default | Required | Rules | Description |\n")
+		b.WriteString("|----------|------|---------|----------|-------|-------------|\n")
+		for _, v := range d {
+			required := ""
+			if v.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n",
+				v.Name, v.Type, markdownCode(v.Default), required, markdownCode(v.Rules), escapeMarkdown(v.Description))
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	default:
+		return fmt.Errorf("describing config: unknown format %q (want table, markdown or json)", format)
+	}
+}
+
+// typeName returns a short, operator-friendly name for t.
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return "duration"
+	case urlType:
+		return "url"
+	case secretType:
+		return "secret"
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "map of " + typeName(t.Key()) + " to " + typeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" && reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return t.Name()
+	}
+	return t.Kind().String()
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + escapeMarkdown(s) + "`"
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
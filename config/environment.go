@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment is the deployment environment a service runs in.
+type Environment string
+
+// Supported environments.
+const (
+	Development Environment = "development"
+	Staging     Environment = "staging"
+	Production  Environment = "production"
+	Test        Environment = "test"
+)
+
+// ParseEnvironment parses an environment name, case-insensitively.
+// The short forms dev, stage and prod are accepted as well.
+func ParseEnvironment(s string) (Environment, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "development", "dev":
+		return Development, nil
+	case "staging", "stage":
+		return Staging, nil
+	case "production", "prod":
+		return Production, nil
+	case "test":
+		return Test, nil
+	default:
+		return "", fmt.Errorf("unknown environment %q (want development, staging, production or test)", s)
+	}
+}
+
+// CurrentEnvironment returns the environment named by the ENVIRONMENT variable,
+// or Development if it is unset. Unrecognised values such as a misspelled
+// "prodution" fail closed to Production, the most restrictive profile; use
+// LoadCommonStrict to reject them.
+func CurrentEnvironment() Environment {
+	env, err := ParseEnvironment(GetEnv("ENVIRONMENT", string(Development)))
+	if err != nil {
+		return Production
+	}
+	return env
+}
+
+// String implements fmt.Stringer.
+func (e Environment) String() string { return string(e) }
+
+// UnmarshalText implements encoding.TextUnmarshaler so Load rejects unknown environments.
+func (e *Environment) UnmarshalText(text []byte) error {
+	env, err := ParseEnvironment(string(text))
+	if err != nil {
+		return err
+	}
+	*e = env
+	return nil
+}
+
+// IsDevelopment reports whether e is Development.
+func (e Environment) IsDevelopment() bool { return e == Development }
+
+// IsStaging reports whether e is Staging.
+func (e Environment) IsStaging() bool { return e == Staging }
+
+// IsProduction reports whether e is Production.
+func (e Environment) IsProduction() bool { return e == Production }
+
+// IsTest reports whether e is Test.
+func (e Environment) IsTest() bool { return e == Test }
+
+// IsDeployed reports whether e is a shared, deployed environment (Staging or
+// Production), where logger.New defaults to JSON output.
+func (e Environment) IsDeployed() bool { return e == Staging || e == Production }
+
+// Profile holds default variable values for one environment.
+type Profile map[string]string
+
+// DefaultProfiles are the CommonGo defaults per environment. Services may copy
+// and extend them with their own variables before passing them to ProfileSource.
+var DefaultProfiles = map[Environment]Profile{
+	Development: {"LOG_LEVEL": "debug"},
+	Test:        {"LOG_LEVEL": "warn"},
+	Staging:     {"LOG_LEVEL": "info"},
+	Production:  {"LOG_LEVEL": "info"},
+}
+
+// ProfileSource returns a Source providing the profile defaults for env.
+// Put it last in NewLayered so any explicitly configured value wins:
+//
+//	env := config.CurrentEnvironment()
+//	src := config.NewLayered(config.EnvSource(), file, config.ProfileSource(env, config.DefaultProfiles))
+func ProfileSource(env Environment, profiles map[Environment]Profile) Source {
+	return mapSource{name: "profile:" + string(env), values: profiles[env]}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource is a Source backed by a YAML, JSON or dotenv file.
+//
+// The format is chosen from the file extension (.yaml/.yml, .json, .env).
+// Nested YAML/JSON objects are flattened into variable names by joining keys
+// with '_' and upper-casing, so
+//
+//	db:
+//	  max_conns: 20
+//
+// provides DB_MAX_CONNS=20. Lists are joined with ',' to match Load's slice parsing.
+type FileSource struct {
+	path string
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewFileSource reads and parses the file at path.
+func NewFileSource(path string) (*FileSource, error) {
+	fs := &FileSource{path: path}
+	if err := fs.Reload(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Name implements Source.
+func (f *FileSource) Name() string { return "file:" + f.path }
+
+// Path returns the path of the underlying file.
+func (f *FileSource) Path() string { return f.path }
+
+// Lookup implements Source.
+func (f *FileSource) Lookup(key string) (string, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// Reload re-reads the file. On error the previously loaded values are kept.
+func (f *FileSource) Reload() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	values, err := parseFile(f.path, data)
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", f.path, err)
+	}
+
+	f.mu.Lock()
+	f.values = values
+	f.mu.Unlock()
+	return nil
+}
+
+func parseFile(path string, data []byte) (map[string]string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var doc map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return flatten(doc), nil
+	case ".json":
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return flatten(doc), nil
+	case ".env":
+		return parseDotenv(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q", ext)
+	}
+}
+
+// flatten converts a decoded YAML/JSON document into variable-name keys.
+func flatten(doc map[string]any) map[string]string {
+	out := make(map[string]string)
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				name := normalizeKey(k)
+				if prefix != "" {
+					name = prefix + "_" + name
+				}
+				walk(name, t[k])
+			}
+		case []any:
+			parts := make([]string, 0, len(t))
+			for _, e := range t {
+				parts = append(parts, scalarString(e))
+			}
+			out[prefix] = strings.Join(parts, ",")
+		case nil:
+			// Explicit nulls leave the key unset.
+		default:
+			out[prefix] = scalarString(t)
+		}
+	}
+	walk("", doc)
+	return out
+}
+
+func scalarString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// parseDotenv parses KEY=VALUE lines, ignoring blank lines and '#' comments.
+// An optional "export " prefix and matching single or double quotes are stripped.
+func parseDotenv(data []byte) (map[string]string, error) {
+	out := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		out[key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
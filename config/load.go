@@ -0,0 +1,370 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissing is reported for required variables that are not set.
+var ErrMissing = errors.New("required variable is not set")
+
+// ErrRecursive is reported for a struct type that contains itself through a
+// nested struct pointer, which would otherwise be walked forever.
+var ErrRecursive = errors.New("recursive struct type")
+
+// FieldError describes a single variable that could not be loaded.
+type FieldError struct {
+	// Key is the fully-prefixed variable name (e.g. DB_MAX_CONNS).
+	Key string
+
+	// Value is the raw value that failed to parse (empty for missing variables).
+	Value string
+
+	// Err is the underlying error (ErrMissing or a parse error).
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if errors.Is(e.Err, ErrMissing) {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s=%q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// LoadError aggregates every FieldError encountered while loading a struct.
+type LoadError struct {
+	Errors []*FieldError
+}
+
+func (e *LoadError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "loading config: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual field errors to errors.Is/errors.As.
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Load populates the struct pointed to by dst from environment variables.
+//
+// Fields are mapped with struct tags:
+//
+//	type Config struct {
+//		Port     int           `env:"PORT" default:"8080"`
+//		Timeout  time.Duration `env:"TIMEOUT" default:"30s"`
+//		Origins  []string      `env:"CORS_ORIGINS" sep:","`
+//		DB       DBConfig      `prefix:"DB_"`
+//		APIToken string        `env:"API_TOKEN" required:"true"`
+//	}
+//
+// Nested structs without an env tag are loaded recursively, with their
+// prefix tag prepended to every variable inside them; nil pointers to them are
+// allocated only if they contain env-tagged fields. Fields tagged env:"-" are
+// skipped, and a struct type nested in itself fails with ErrRecursive. Slices are split on sep
+// (default ","), maps use "key:value" pairs separated by sep. Supported types
+// are strings, bools, ints, uints, floats, time.Duration, url.URL and any type
+// implementing encoding.TextUnmarshaler, plus pointers, slices and maps of those.
+//
+// Values are checked against the field's validate tag (see Validate).
+// Empty variables are treated as unset, matching GetEnv. Load does not stop at
+// the first problem: the returned *LoadError lists every missing, malformed or
+// invalid variable.
+func Load(dst any) error {
+	return load(dst, "", os.LookupEnv)
+}
+
+// MustLoad is like Load but panics on error.
+func MustLoad(dst any) {
+	if err := Load(dst); err != nil {
+		panic(err)
+	}
+}
+
+func load(dst any, prefix string, lookup func(string) (string, bool)) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("loading config: destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+
+	var loadErr LoadError
+	err := walkFields(rv.Elem(), prefix, true, func(f field) {
+		raw, ok := lookup(f.key)
+		if !ok || raw == "" {
+			if f.required {
+				loadErr.Errors = append(loadErr.Errors, &FieldError{Key: f.key, Err: ErrMissing})
+				return
+			}
+			if !f.hasDefault {
+				return
+			}
+			raw = f.defaultValue
+		}
+		if err := setValue(f.value, raw, f.sep); err != nil {
+			loadErr.Errors = append(loadErr.Errors, &FieldError{Key: f.key, Value: raw, Err: err})
+			return
+		}
+		if fe := validateField(f); fe != nil {
+			loadErr.Errors = append(loadErr.Errors, fe)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	if len(loadErr.Errors) > 0 {
+		return &loadErr
+	}
+	return nil
+}
+
+// field is a single tagged struct field resolved to its full variable name.
+type field struct {
+	key          string
+	value        reflect.Value
+	structField  reflect.StructField
+	defaultValue string
+	hasDefault   bool
+	required     bool
+	sep          string
+}
+
+// walkFields calls fn for every env-tagged field reachable from v, descending
+// into nested and embedded structs. Fields tagged env:"-" are skipped. Nil
+// nested struct pointers are allocated when alloc is true and the struct has
+// env-tagged fields, and skipped otherwise. A struct type nested in itself is
+// reported as ErrRecursive instead of being walked forever.
+func walkFields(v reflect.Value, prefix string, alloc bool, fn func(field)) error {
+	return walkStruct(v, prefix, alloc, fn, map[reflect.Type]bool{v.Type(): true})
+}
+
+// walkStruct implements walkFields; path holds the struct types being walked.
+func walkStruct(v reflect.Value, prefix string, alloc bool, fn func(field), path map[reflect.Type]bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		name, hasEnv := sf.Tag.Lookup("env")
+		if name == "-" {
+			continue
+		}
+		if !hasEnv || name == "" {
+			if isNestedStruct(sf.Type) {
+				nested := structType(sf.Type)
+				if fv.Kind() == reflect.Pointer && fv.IsNil() && (!alloc || !hasEnvFields(nested, map[reflect.Type]bool{})) {
+					continue
+				}
+				if path[nested] {
+					return fmt.Errorf("field %s.%s: %w %s", t.Name(), sf.Name, ErrRecursive, nested)
+				}
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						fv.Set(reflect.New(nested))
+					}
+					fv = fv.Elem()
+				}
+				path[nested] = true
+				err := walkStruct(fv, prefix+sf.Tag.Get("prefix"), alloc, fn, path)
+				delete(path, nested)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		def, hasDef := sf.Tag.Lookup("default")
+		sep := sf.Tag.Get("sep")
+		if sep == "" {
+			sep = ","
+		}
+		required, _ := strconv.ParseBool(sf.Tag.Get("required"))
+
+		fn(field{
+			key:          prefix + name,
+			value:        fv,
+			structField:  sf,
+			defaultValue: def,
+			hasDefault:   hasDef,
+			required:     required,
+			sep:          sep,
+		})
+	}
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isNestedStruct reports whether t is a struct (or pointer to one) that should
+// be walked rather than parsed from a single value.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == urlType {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// hasEnvFields reports whether the struct type t, or a struct nested in it,
+// has an env-tagged field. seen holds the types already checked, so recursive
+// types terminate.
+func hasEnvFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, hasEnv := sf.Tag.Lookup("env")
+		switch {
+		case name == "-":
+		case hasEnv && name != "":
+			return true
+		case isNestedStruct(sf.Type):
+			if hasEnvFields(structType(sf.Type), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// structType returns t, or its element type if t is a pointer.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// setValue parses raw into v according to v's type.
+func setValue(v reflect.Value, raw, sep string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), raw, sep)
+	}
+
+	if v.CanAddr() {
+		if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return tu.UnmarshalText([]byte(raw))
+		}
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case urlType:
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := splitList(raw, sep)
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setValue(s.Index(i), p, sep); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range splitList(raw, sep) {
+			k, val, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("map entry %q: expected key:value", pair)
+			}
+			mk := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(mk, strings.TrimSpace(k), sep); err != nil {
+				return fmt.Errorf("map key %q: %w", k, err)
+			}
+			mv := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(mv, strings.TrimSpace(val), sep); err != nil {
+				return fmt.Errorf("map value for %q: %w", k, err)
+			}
+			m.SetMapIndex(mk, mv)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits raw on sep, trimming whitespace and dropping empty entries.
+func splitList(raw, sep string) []string {
+	var out []string
+	for _, p := range strings.Split(raw, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name string `env:"NAME"`
+	Next *node  `prefix:"NEXT_"`
+}
+
+// tree recurses but has no env-tagged fields, so it is never walked.
+type tree struct {
+	Children []tree
+	Parent   *tree
+}
+
+func TestLoadRecursiveType(t *testing.T) {
+	src := MapSource("test", map[string]string{"NAME": "a", "NEXT_NAME": "b"})
+
+	done := make(chan error, 1)
+	go func() {
+		var n node
+		done <- LoadFrom(&n, src)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrRecursive) {
+			t.Fatalf("LoadFrom() error = %v, want ErrRecursive", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LoadFrom() did not return for a recursive type")
+	}
+
+	if _, err := Describe(&node{}); !errors.Is(err, ErrRecursive) {
+		t.Errorf("Describe() error = %v, want ErrRecursive", err)
+	}
+	if err := Validate(&node{Name: "a", Next: &node{Name: "b"}}); !errors.Is(err, ErrRecursive) {
+		t.Errorf("Validate() error = %v, want ErrRecursive", err)
+	}
+}
+
+func TestLoadRepeatedType(t *testing.T) {
+	type db struct {
+		Name string `env:"NAME"`
+	}
+	// The same type may appear more than once as long as it doesn't nest in itself.
+	var cfg struct {
+		Primary db `prefix:"PRIMARY_"`
+		Replica db `prefix:"REPLICA_"`
+		Tree    *tree
+	}
+	if err := LoadFrom(&cfg, MapSource("test", map[string]string{"PRIMARY_NAME": "db"})); err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.Primary.Name != "db" || cfg.Replica.Name != "" || cfg.Tree != nil {
+		t.Errorf("LoadFrom() = %+v", cfg)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Redacted is what a Secret renders as in logs, JSON and fmt output.
+const Redacted = "[REDACTED]"
+
+// Secret holds a sensitive value such as a password or token.
+//
+// Its String, GoString, MarshalJSON and MarshalText methods always render
+// "[REDACTED]", so a Secret passed to fmt or to zerolog (Interface, Any,
+// Stringer) never leaks its value. Use Value to obtain the real value.
+//
+// When loaded with Load or LoadFrom, a value of the form scheme://ref is
+// resolved through the SecretResolver registered for scheme:
+//
+//	DB_PASSWORD=file:///run/secrets/db_password
+//	API_TOKEN=env://LEGACY_API_TOKEN
+//
+// Values with an unregistered scheme (including postgres:// URLs) are kept literally.
+type Secret struct {
+	value string
+}
+
+// NewSecret wraps value in a Secret.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the secret value.
+func (s Secret) Value() string { return s.value }
+
+// IsZero reports whether the secret is empty.
+func (s Secret) IsZero() bool { return s.value == "" }
+
+// String implements fmt.Stringer.
+func (s Secret) String() string { return Redacted }
+
+// GoString implements fmt.GoStringer so %#v is redacted too.
+func (s Secret) GoString() string { return Redacted }
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(Redacted) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Secret) MarshalText() ([]byte, error) { return []byte(Redacted), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler, resolving secret references.
+func (s *Secret) UnmarshalText(text []byte) error {
+	v, err := ResolveSecret(context.Background(), string(text))
+	if err != nil {
+		return err
+	}
+	s.value = v
+	return nil
+}
+
+var secretType = reflect.TypeOf(Secret{})
+
+// isSecret reports whether t is a Secret or a pointer to one.
+func isSecret(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == secretType
+}
+
+// SecretResolver fetches secret values from a backend such as a mounted
+// volume or a vault-like service.
+type SecretResolver interface {
+	// Resolve returns the secret identified by ref, the reference with its
+	// "scheme://" prefix removed.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve implements SecretResolver.
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"file": SecretResolverFunc(resolveFileSecret),
+		"env":  SecretResolverFunc(resolveEnvSecret),
+	}
+)
+
+// RegisterSecretResolver makes r handle references with the given scheme,
+// replacing any previous resolver. The file and env schemes are registered by default.
+//
+// For local development a backend can be stubbed with MapSecretResolver:
+//
+//	config.RegisterSecretResolver("vault", config.MapSecretResolver(map[string]string{
+//		"db/password": "dev",
+//	}))
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// MapSecretResolver returns a SecretResolver backed by an in-memory map.
+func MapSecretResolver(values map[string]string) SecretResolver {
+	return SecretResolverFunc(func(_ context.Context, ref string) (string, error) {
+		v, ok := values[ref]
+		if !ok {
+			return "", fmt.Errorf("secret %q not found", ref)
+		}
+		return v, nil
+	})
+}
+
+// ResolveSecret resolves value if it is a reference with a registered scheme,
+// and returns it unchanged otherwise.
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok {
+		return value, nil
+	}
+
+	resolversMu.RLock()
+	r, ok := resolvers[scheme]
+	resolversMu.RUnlock()
+	if !ok {
+		return value, nil
+	}
+
+	v, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s secret: %w", scheme, err)
+	}
+	return v, nil
+}
+
+// resolveFileSecret reads a mounted secret, trimming a trailing newline.
+func resolveFileSecret(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveEnvSecret(_ context.Context, key string) (string, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrMissing)
+	}
+	return v, nil
+}
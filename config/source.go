@@ -0,0 +1,182 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Source provides raw configuration values keyed by variable name
+// (e.g. DB_MAX_CONNS). Sources are combined with Layered and consumed by LoadFrom.
+type Source interface {
+	// Name identifies the source in provenance reports (e.g. "env", "file:config.yaml").
+	Name() string
+
+	// Lookup returns the raw value for key and whether it was present.
+	Lookup(key string) (string, bool)
+}
+
+type envSource struct{}
+
+// EnvSource returns a Source backed by the process environment.
+func EnvSource() Source { return envSource{} }
+
+func (envSource) Name() string { return "env" }
+
+func (envSource) Lookup(key string) (string, bool) { return os.LookupEnv(key) }
+
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+// MapSource returns an in-memory Source, useful for tests and programmatic overrides.
+func MapSource(name string, values map[string]string) Source {
+	return mapSource{name: name, values: values}
+}
+
+func (s mapSource) Name() string { return s.name }
+
+func (s mapSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+type flagSource struct {
+	fs *flag.FlagSet
+}
+
+// FlagSource returns a Source backed by a parsed flag.FlagSet.
+// Only flags set explicitly on the command line are visible, so unset flags
+// never shadow lower-precedence sources. Flag names map to keys by upper-casing
+// and replacing '-' and '.' with '_' (--db-max-conns becomes DB_MAX_CONNS).
+func FlagSource(fs *flag.FlagSet) Source {
+	return flagSource{fs: fs}
+}
+
+func (flagSource) Name() string { return "flag" }
+
+func (s flagSource) Lookup(key string) (string, bool) {
+	var (
+		value string
+		found bool
+	)
+	s.fs.Visit(func(f *flag.Flag) {
+		if normalizeKey(f.Name) == key {
+			value, found = f.Value.String(), true
+		}
+	})
+	return value, found
+}
+
+// Layered merges several sources. Sources are consulted in the order given,
+// so the first source has the highest precedence:
+//
+//	src := config.NewLayered(
+//		config.FlagSource(flag.CommandLine),
+//		config.EnvSource(),
+//		file,
+//	)
+//
+// Struct tag defaults apply only when no source provides a value.
+type Layered struct {
+	sources []Source
+}
+
+// NewLayered returns a Source that merges sources, highest precedence first.
+func NewLayered(sources ...Source) *Layered {
+	return &Layered{sources: sources}
+}
+
+// Name implements Source.
+func (l *Layered) Name() string {
+	names := make([]string, 0, len(l.sources))
+	for _, s := range l.sources {
+		names = append(names, s.Name())
+	}
+	return "layered(" + strings.Join(names, ",") + ")"
+}
+
+// Lookup implements Source, returning the value from the highest-precedence
+// source that has a non-empty value for key.
+func (l *Layered) Lookup(key string) (string, bool) {
+	v, _, ok := l.lookup(key)
+	return v, ok
+}
+
+// Origin returns the name of the source that provides key, or "" if none does.
+func (l *Layered) Origin(key string) string {
+	_, name, _ := l.lookup(key)
+	return name
+}
+
+func (l *Layered) lookup(key string) (string, string, bool) {
+	for _, s := range l.sources {
+		if v, ok := s.Lookup(key); ok && v != "" {
+			return v, s.Name(), true
+		}
+	}
+	return "", "", false
+}
+
+// Provenance records where the effective value of a variable came from.
+type Provenance struct {
+	// Key is the fully-prefixed variable name.
+	Key string
+
+	// Value is the effective raw value ("" if unset).
+	Value string
+
+	// Source is the providing source name, "default" for tag defaults, or "unset".
+	Source string
+}
+
+// Explain reports, for every tagged field of the struct pointed to by dst,
+// the effective raw value and the source it came from. dst is not modified.
+// Values of Secret fields are reported as "[REDACTED]".
+func (l *Layered) Explain(dst any) ([]Provenance, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("explaining config: destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+
+	// Walk a scratch copy so nil nested pointers in dst are left untouched.
+	scratch := reflect.New(rv.Elem().Type())
+
+	var out []Provenance
+	err := walkFields(scratch.Elem(), "", true, func(f field) {
+		p := Provenance{Key: f.key, Source: "unset"}
+		if v, name, ok := l.lookup(f.key); ok {
+			p.Value, p.Source = v, name
+		} else if f.hasDefault {
+			p.Value, p.Source = f.defaultValue, "default"
+		}
+		if p.Value != "" && isSecret(f.structField.Type) {
+			p.Value = Redacted
+		}
+		out = append(out, p)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explaining config: %w", err)
+	}
+	return out, nil
+}
+
+// LoadFrom is like Load but reads values from src instead of the environment.
+func LoadFrom(dst any, src Source) error {
+	return load(dst, "", src.Lookup)
+}
+
+// LoadPrefixed is like LoadFrom but prepends prefix to every variable name,
+// so one struct type can be loaded several times from differently prefixed
+// variables (e.g. PG_URL and REPORTING_PG_URL).
+func LoadPrefixed(dst any, prefix string, src Source) error {
+	return load(dst, prefix, src.Lookup)
+}
+
+// normalizeKey converts a flag or file key into variable-name form.
+func normalizeKey(k string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(k))
+}
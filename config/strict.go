@@ -0,0 +1,66 @@
+package config
+
+import "time"
+
+// Strict collects parse failures from a sequence of lookups so a service can
+// report every misconfigured variable at startup instead of the first one.
+//
+//	var s config.Strict
+//	port := s.Int("PORT", 8080)
+//	timeout := s.Duration("TIMEOUT", 30*time.Second)
+//	if err := s.Err(); err != nil {
+//		log.Fatal().Err(err).Msg("invalid configuration")
+//	}
+//
+// The zero value is ready to use. Malformed values return the default and are
+// recorded; call Err once all lookups are done.
+type Strict struct {
+	errs []*FieldError
+}
+
+// String retrieves an environment variable or returns a default value.
+func (s *Strict) String(key, defaultValue string) string {
+	return GetEnv(key, defaultValue)
+}
+
+// Int retrieves an environment variable as an integer, recording malformed values.
+func (s *Strict) Int(key string, defaultValue int) int {
+	v, err := LookupInt(key, defaultValue)
+	s.record(err)
+	return v
+}
+
+// Int32 retrieves an environment variable as an int32, recording malformed values.
+func (s *Strict) Int32(key string, defaultValue int32) int32 {
+	v, err := LookupInt32(key, defaultValue)
+	s.record(err)
+	return v
+}
+
+// Duration retrieves an environment variable as a duration, recording malformed values.
+func (s *Strict) Duration(key string, defaultValue time.Duration) time.Duration {
+	v, err := LookupDuration(key, defaultValue)
+	s.record(err)
+	return v
+}
+
+// Bool retrieves an environment variable as a boolean, recording malformed values.
+func (s *Strict) Bool(key string, defaultValue bool) bool {
+	v, err := LookupBool(key, defaultValue)
+	s.record(err)
+	return v
+}
+
+// Err returns a *LoadError listing every malformed variable, or nil.
+func (s *Strict) Err() error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return &LoadError{Errors: s.errs}
+}
+
+func (s *Strict) record(err error) {
+	if fe, ok := err.(*FieldError); ok {
+		s.errs = append(s.errs, fe)
+	}
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError aggregates every FieldError reported by Validate.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "validating config: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual field errors to errors.Is/errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Validate checks every tagged field of the struct pointed to by dst against
+// the rules in its validate tag:
+//
+//	type Config struct {
+//		Port     int           `env:"PORT" validate:"min=1,max=65535"`
+//		Timeout  time.Duration `env:"TIMEOUT" validate:"min=1s,max=5m"`
+//		LogLevel string        `env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+//		Name     string        `env:"NAME" validate:"max=63,regex=^[a-z][a-z0-9-]*$"`
+//		Endpoint string        `env:"ENDPOINT" validate:"url"`
+//		Addr     string        `env:"ADDR" validate:"hostport"`
+//	}
+//
+// min and max bound numbers and durations by value and strings, slices and
+// maps by length. oneof takes space-separated values. regex takes the rest of
+// the tag, so it must be the last rule. url requires a scheme and host;
+// hostport requires host:port with a numeric port.
+//
+// Zero values are not validated; use required:"true" to demand a value.
+// Load and LoadFrom run the same rules on every value they set, so Validate
+// is only needed for structs built or modified by other means.
+func Validate(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validating config: expected a struct or pointer to a struct, got %T", dst)
+	}
+
+	var verr ValidationError
+	err := walkFields(rv, "", false, func(f field) {
+		if f.value.IsZero() {
+			return
+		}
+		if err := validateField(f); err != nil {
+			verr.Errors = append(verr.Errors, err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("validating config: %w", err)
+	}
+
+	if len(verr.Errors) > 0 {
+		return &verr
+	}
+	return nil
+}
+
+// rule is a single parsed validate tag entry.
+type rule struct {
+	name string
+	arg  string
+}
+
+// parseRules splits a validate tag into rules. Everything after "regex=" is
+// taken as the pattern so it may contain commas.
+func parseRules(tag string) []rule {
+	var rules []rule
+	for tag != "" {
+		if strings.HasPrefix(tag, "regex=") {
+			rules = append(rules, rule{name: "regex", arg: strings.TrimPrefix(tag, "regex=")})
+			break
+		}
+		var part string
+		part, tag, _ = strings.Cut(tag, ",")
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			rules = append(rules, rule{name: name, arg: arg})
+		}
+	}
+	return rules
+}
+
+// validateField applies f's validate tag to its current value.
+func validateField(f field) *FieldError {
+	tag := f.structField.Tag.Get("validate")
+	if tag == "" {
+		return nil
+	}
+
+	v := f.value
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	for _, r := range parseRules(tag) {
+		if err := applyRule(r, v); err != nil {
+			return &FieldError{Key: f.key, Value: displayValue(v), Err: err}
+		}
+	}
+	return nil
+}
+
+// displayValue formats v for error messages; Secret values stay redacted.
+func displayValue(v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func applyRule(r rule, v reflect.Value) error {
+	switch r.name {
+	case "min", "max":
+		return checkBound(r, v)
+	case "oneof":
+		s := stringValue(v)
+		for _, opt := range strings.Fields(r.arg) {
+			if s == opt {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s]", strings.Join(strings.Fields(r.arg), ", "))
+	case "regex":
+		re, err := regexp.Compile(r.arg)
+		if err != nil {
+			return fmt.Errorf("invalid regex rule %q: %w", r.arg, err)
+		}
+		if !re.MatchString(stringValue(v)) {
+			return fmt.Errorf("must match %s", r.arg)
+		}
+		return nil
+	case "url":
+		u, err := url.Parse(stringValue(v))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL with scheme and host")
+		}
+		return nil
+	case "hostport":
+		_, port, err := net.SplitHostPort(stringValue(v))
+		if err != nil {
+			return fmt.Errorf("must be host:port: %w", err)
+		}
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+			return fmt.Errorf("port %q must be a number between 1 and 65535", port)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown validate rule %q", r.name)
+	}
+}
+
+// stringValue returns the underlying string of v, including Secret values.
+func stringValue(v reflect.Value) string {
+	if s, ok := v.Interface().(Secret); ok {
+		return s.Value()
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return displayValue(v)
+}
+
+func checkBound(r rule, v reflect.Value) error {
+	if n, ok := length(v); ok {
+		bound, err := strconv.Atoi(r.arg)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule %q: %w", r.name, r.arg, err)
+		}
+		if r.name == "min" && n < bound {
+			return fmt.Errorf("length must be at least %d", bound)
+		}
+		if r.name == "max" && n > bound {
+			return fmt.Errorf("length must be at most %d", bound)
+		}
+		return nil
+	}
+
+	var (
+		cmp int
+		err error
+	)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			var bound time.Duration
+			if bound, err = time.ParseDuration(r.arg); err == nil {
+				cmp = compare(time.Duration(v.Int()), bound)
+			}
+			break
+		}
+		var bound int64
+		if bound, err = strconv.ParseInt(r.arg, 10, 64); err == nil {
+			cmp = compare(v.Int(), bound)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var bound uint64
+		if bound, err = strconv.ParseUint(r.arg, 10, 64); err == nil {
+			cmp = compare(v.Uint(), bound)
+		}
+	case reflect.Float32, reflect.Float64:
+		var bound float64
+		if bound, err = strconv.ParseFloat(r.arg, 64); err == nil {
+			cmp = compare(v.Float(), bound)
+		}
+	default:
+		return fmt.Errorf("%s rule not supported for type %s", r.name, v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s rule %q: %w", r.name, r.arg, err)
+	}
+
+	if r.name == "min" && cmp < 0 {
+		return fmt.Errorf("must be at least %s", r.arg)
+	}
+	if r.name == "max" && cmp > 0 {
+		return fmt.Errorf("must be at most %s", r.arg)
+	}
+	return nil
+}
+
+// length returns the length of strings, Secrets, slices and maps.
+func length(v reflect.Value) (int, bool) {
+	if v.Type() == secretType {
+		return len(v.Interface().(Secret).Value()), true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+func compare[T int64 | uint64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WatchOptions configures a Watcher.
+type WatchOptions[T any] struct {
+	// Interval is how often watched files are polled for changes (default: 2s).
+	Interval time.Duration
+
+	// Validate, if set, is called on every new snapshot before it is swapped in.
+	// A snapshot that fails validation is discarded and the current one is kept.
+	Validate func(*T) error
+
+	// OnError, if set, is called when a reload fails (read, parse, load or validation errors).
+	OnError func(error)
+}
+
+// Watcher reloads a config struct of type T from a Source whenever one of its
+// files changes, and publishes each new snapshot to subscribers.
+//
+//	file, _ := config.NewFileSource("config.yaml")
+//	w, err := config.NewWatcher[Config](config.NewLayered(config.EnvSource(), file),
+//		[]*config.FileSource{file}, config.WatchOptions[Config]{})
+//	if err != nil {
+//		log.Fatal().Err(err).Msg("invalid configuration")
+//	}
+//	w.Subscribe(func(old, cur Config) {
+//		level.Set(cur.LogLevel)
+//	})
+//	go w.Run(ctx)
+//
+// Files are polled by modification time and size, which works on every
+// platform and with mounted ConfigMaps where inotify events are unreliable.
+type Watcher[T any] struct {
+	src   Source
+	files []*FileSource
+	opts  WatchOptions[T]
+
+	mu      sync.RWMutex
+	current T
+	stamps  []fileStamp
+	subs    map[int]func(old, cur T)
+	nextSub int
+
+	reloadMu sync.Mutex
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher loads and validates the initial snapshot of T from src.
+// files are the file sources backing src that should be polled for changes.
+func NewWatcher[T any](src Source, files []*FileSource, opts WatchOptions[T]) (*Watcher[T], error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	w := &Watcher[T]{
+		src:   src,
+		files: files,
+		opts:  opts,
+		subs:  make(map[int]func(old, cur T)),
+	}
+
+	cur, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.current = cur
+	w.stamps = w.statFiles()
+	return w, nil
+}
+
+// Current returns the most recent valid snapshot.
+func (w *Watcher[T]) Current() T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Subscribe registers fn to be called with the previous and new snapshot
+// after every change. Subscribers are called sequentially from the goroutine
+// performing the reload. The returned function removes the subscription.
+func (w *Watcher[T]) Subscribe(fn func(old, cur T)) (unsubscribe func()) {
+	w.mu.Lock()
+	id := w.nextSub
+	w.nextSub++
+	w.subs[id] = fn
+	w.mu.Unlock()
+
+	return func() {
+		w.mu.Lock()
+		delete(w.subs, id)
+		w.mu.Unlock()
+	}
+}
+
+// Run polls the watched files until ctx is cancelled, reloading when any of
+// them changes. It always returns ctx.Err().
+func (w *Watcher[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+			if err := w.Reload(); err != nil && w.opts.OnError != nil {
+				w.opts.OnError(err)
+			}
+		}
+	}
+}
+
+// Reload re-reads every watched file and reloads the snapshot immediately,
+// e.g. from a SIGHUP handler. Subscribers are notified only if the new,
+// validated snapshot differs from the current one.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	stamps := w.statFiles()
+	for _, f := range w.files {
+		if err := f.Reload(); err != nil {
+			w.setStamps(stamps)
+			return err
+		}
+	}
+
+	next, err := w.load()
+	w.setStamps(stamps)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	old := w.current
+	if reflect.DeepEqual(old, next) {
+		w.mu.Unlock()
+		return nil
+	}
+	w.current = next
+	subs := make([]func(old, cur T), 0, len(w.subs))
+	for _, fn := range w.subs {
+		subs = append(subs, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(old, next)
+	}
+	return nil
+}
+
+func (w *Watcher[T]) load() (T, error) {
+	var next T
+	if err := LoadFrom(&next, w.src); err != nil {
+		return next, err
+	}
+	if w.opts.Validate != nil {
+		if err := w.opts.Validate(&next); err != nil {
+			return next, fmt.Errorf("validating config: %w", err)
+		}
+	}
+	return next, nil
+}
+
+// changed reports whether any watched file differs from the last reload.
+func (w *Watcher[T]) changed() bool {
+	stamps := w.statFiles()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return !reflect.DeepEqual(stamps, w.stamps)
+}
+
+func (w *Watcher[T]) setStamps(stamps []fileStamp) {
+	w.mu.Lock()
+	w.stamps = stamps
+	w.mu.Unlock()
+}
+
+func (w *Watcher[T]) statFiles() []fileStamp {
+	stamps := make([]fileStamp, len(w.files))
+	for i, f := range w.files {
+		// Missing files get a zero stamp so their reappearance triggers a reload.
+		if fi, err := os.Stat(f.Path()); err == nil {
+			stamps[i] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+		}
+	}
+	return stamps
+}
@@ -0,0 +1,244 @@
+package grpcx
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	// Subject identifies the caller: the JWT "sub" claim, or the mTLS
+	// certificate identity (URI SAN such as a SPIFFE ID, else DNS SAN, else CN).
+	Subject string
+
+	// Method is how the caller authenticated: "jwt" or "mtls".
+	Method string
+
+	// Issuer and Audience are the JWT "iss" and "aud" claims.
+	Issuer   string
+	Audience []string
+
+	// Scopes are the granted scopes, checked against AuthOptions.RequiredScopes.
+	Scopes []string
+
+	// Claims holds every JWT claim; nil for mTLS.
+	Claims map[string]any
+
+	// Certificate is the verified client certificate; nil for JWT.
+	Certificate *x509.Certificate
+}
+
+// HasScope reports whether p was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a context carrying p.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the Principal set by the auth interceptors, or nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// ErrNoCredentials is returned by an Authenticator when the call carries none
+// of the credentials it handles, so the next Authenticator is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator identifies the caller of an RPC from its context (metadata,
+// peer certificates). It returns ErrNoCredentials when its kind of credential
+// is absent and another error when the credential is invalid.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(ctx context.Context) (*Principal, error)
+
+// Authenticate calls f(ctx).
+func (f AuthenticatorFunc) Authenticate(ctx context.Context) (*Principal, error) {
+	return f(ctx)
+}
+
+// MTLSOptions configures MTLSAuthenticator.
+type MTLSOptions struct {
+	// AllowedIdentities, if set, restricts the accepted certificate identities
+	// (see Principal.Subject). Empty accepts any certificate the server's TLS
+	// configuration verified.
+	AllowedIdentities []string
+
+	// Scopes grants scopes by certificate identity.
+	Scopes map[string][]string
+}
+
+// MTLSAuthenticator identifies callers by their client certificate. Only
+// certificates verified by the TLS handshake are used, so the server must be
+// configured with a client CA and tls.RequireAndVerifyClientCert (or
+// VerifyClientCertIfGiven).
+func MTLSAuthenticator(opts MTLSOptions) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context) (*Principal, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return nil, ErrNoCredentials
+		}
+		info, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+			return nil, ErrNoCredentials
+		}
+
+		cert := info.State.VerifiedChains[0][0]
+		id := certIdentity(cert)
+		if len(opts.AllowedIdentities) > 0 && !slices.Contains(opts.AllowedIdentities, id) {
+			return nil, errors.New("client certificate identity " + id + " not allowed")
+		}
+		return &Principal{
+			Subject:     id,
+			Method:      "mtls",
+			Scopes:      opts.Scopes[id],
+			Certificate: cert,
+		}, nil
+	})
+}
+
+// certIdentity returns the first URI SAN (e.g. spiffe://...), else the first
+// DNS SAN, else the subject common name.
+func certIdentity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.CommonName
+}
+
+// DefaultAuthSkipMethods are the methods AuthOptions.SkipMethods defaults to:
+// the health and reflection services, which probes and tooling call without credentials.
+var DefaultAuthSkipMethods = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// AuthOptions configures the auth interceptors.
+type AuthOptions struct {
+	// Authenticators are tried in order; the first one finding its kind of
+	// credential decides (e.g. JWTAuthenticator, then MTLSAuthenticator).
+	Authenticators []Authenticator
+
+	// SkipMethods are full methods ("/pkg.Service/Method") or services
+	// ("/pkg.Service/") served without authentication. Nil uses DefaultAuthSkipMethods.
+	SkipMethods []string
+
+	// RequiredScopes maps full methods or services ("/pkg.Service/") to scopes
+	// the principal must all have. A method entry takes precedence over its service's.
+	RequiredScopes map[string][]string
+}
+
+// UnaryAuthInterceptor authenticates unary RPCs and puts the Principal into
+// the context (see PrincipalFromContext) and its subject into the context
+// logger. Calls without valid credentials fail with codes.Unauthenticated,
+// calls missing a required scope with codes.PermissionDenied.
+func UnaryAuthInterceptor(opts AuthOptions) grpc.UnaryServerInterceptor {
+	a := newAuthorizer(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor is the stream counterpart of UnaryAuthInterceptor.
+func StreamAuthInterceptor(opts AuthOptions) grpc.StreamServerInterceptor {
+	a := newAuthorizer(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authorizer struct {
+	opts AuthOptions
+}
+
+func newAuthorizer(opts AuthOptions) *authorizer {
+	if opts.SkipMethods == nil {
+		opts.SkipMethods = DefaultAuthSkipMethods
+	}
+	return &authorizer{opts: opts}
+}
+
+func (a *authorizer) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if matchMethod(a.opts.SkipMethods, fullMethod) {
+		return ctx, nil
+	}
+
+	var principal *Principal
+	for _, auth := range a.opts.Authenticators {
+		p, err := auth.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			log := logger.From(ctx)
+			log.Debug().Err(err).Str("grpc_method", fullMethod).Msg("authentication failed")
+			return ctx, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		principal = p
+		break
+	}
+	if principal == nil {
+		return ctx, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+
+	for _, scope := range a.requiredScopes(fullMethod) {
+		if !principal.HasScope(scope) {
+			return ctx, status.Errorf(codes.PermissionDenied, "missing scope %q", scope)
+		}
+	}
+
+	ctx = ContextWithPrincipal(ctx, principal)
+	log := logger.From(ctx).With().Str("principal", principal.Subject).Logger()
+	return logger.With(ctx, log), nil
+}
+
+func (a *authorizer) requiredScopes(fullMethod string) []string {
+	if scopes, ok := a.opts.RequiredScopes[fullMethod]; ok {
+		return scopes
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		return a.opts.RequiredScopes[fullMethod[:i+1]]
+	}
+	return nil
+}
+
+// matchMethod reports whether fullMethod is listed in methods, directly or via its service ("/pkg.Service/").
+func matchMethod(methods []string, fullMethod string) bool {
+	for _, m := range methods {
+		if m == fullMethod || (strings.HasSuffix(m, "/") && strings.HasPrefix(fullMethod, m)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package grpcx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/nikolapavicevic-001/CommonGo/config"
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
+)
+
+// ClientOptions configures a client connection built by Dial.
+//
+// Like Options, zero transport values fall back to the defaults noted on each
+// field, and the env tags allow loading them with ClientOptionsFromEnv or config.Load.
+type ClientOptions struct {
+	// Logger is used by the client logging interceptors. It must be set (zerolog.Nop() disables logging).
+	Logger zerolog.Logger
+
+	// Logging configures the client logging interceptors (slow call thresholds, ...).
+	Logging LoggingOptions
+
+	// EnableOTel enables OpenTelemetry gRPC instrumentation (stats handler).
+	EnableOTel bool
+
+	// DefaultTimeout is applied to unary calls whose context has no deadline (0: none).
+	// Deadlines already on the context are propagated to the server unchanged.
+	DefaultTimeout time.Duration `env:"DEFAULT_TIMEOUT" default:"10s" desc:"Timeout for unary calls without a deadline (0: none)"`
+
+	// LoadBalancingPolicy is round_robin or pick_first (default: round_robin).
+	// round_robin spreads calls across every address the resolver returns, e.g. for dns:///svc:9090.
+	LoadBalancingPolicy string `env:"LOAD_BALANCING_POLICY" default:"round_robin" validate:"oneof=round_robin pick_first" desc:"Load balancing policy"`
+
+	// RetryMaxAttempts is the maximum number of attempts, including the first,
+	// for calls failing with UNAVAILABLE (default: 3; 1 disables retries; gRPC caps it at 5)
+	RetryMaxAttempts int `env:"RETRY_MAX_ATTEMPTS" default:"3" validate:"min=1,max=5" desc:"Maximum attempts for UNAVAILABLE calls (1 disables retries)"`
+
+	// RetryInitialBackoff and RetryMaxBackoff bound the randomized delay between attempts (default: 100ms, 1s)
+	RetryInitialBackoff time.Duration `env:"RETRY_INITIAL_BACKOFF" default:"100ms" validate:"min=1ms" desc:"Initial retry backoff"`
+	RetryMaxBackoff     time.Duration `env:"RETRY_MAX_BACKOFF" default:"1s" validate:"min=1ms" desc:"Maximum retry backoff"`
+
+	// Retry, if set, installs UnaryClientRetryInterceptor with these options
+	// (per-method policies, more codes, hedging) in place of the service config
+	// retry policy, so attempts are not multiplied.
+	Retry *RetryOptions `env:"-"`
+
+	// ServiceConfig, if set, is a JSON service config used instead of the one
+	// built from LoadBalancingPolicy and the retry fields.
+	ServiceConfig string `env:"SERVICE_CONFIG" desc:"JSON service config overriding the load balancing and retry settings"`
+
+	// MaxRecvMsgSize and MaxSendMsgSize limit message sizes in bytes (default: 4 MiB)
+	MaxRecvMsgSize int `env:"MAX_RECV_MSG_SIZE" default:"4194304" validate:"min=1024" desc:"Maximum received message size in bytes"`
+	MaxSendMsgSize int `env:"MAX_SEND_MSG_SIZE" default:"4194304" validate:"min=1024" desc:"Maximum sent message size in bytes"`
+
+	// KeepaliveTime is how long a connection may be idle before the client pings
+	// the server (default: 30s; must not be below the server's KeepaliveMinTime)
+	KeepaliveTime time.Duration `env:"KEEPALIVE_TIME" default:"30s" validate:"min=10s" desc:"Idle time before the client pings the server"`
+
+	// KeepaliveTimeout is how long the client waits for a ping ack before closing the connection (default: 10s)
+	KeepaliveTimeout time.Duration `env:"KEEPALIVE_TIMEOUT" default:"10s" validate:"min=1s" desc:"Ping ack timeout before closing the connection"`
+
+	// KeepalivePermitWithoutStream pings even without active RPCs, detecting
+	// dead connections before the next call. DefaultClientOptions and ClientOptionsFromEnv enable it.
+	KeepalivePermitWithoutStream bool `env:"KEEPALIVE_PERMIT_WITHOUT_STREAM" default:"true" desc:"Ping without active RPCs"`
+
+	// TLS enables TLS, verifying the server against the system roots or TLSCAFile.
+	// It is implied by any other TLS field. Without TLS the connection is plaintext.
+	TLS bool `env:"TLS" desc:"Enable TLS"`
+
+	// TLSCAFile is a PEM bundle of CAs trusted to sign the server certificate.
+	TLSCAFile string `env:"TLS_CA_FILE" desc:"PEM CA bundle verifying the server"`
+
+	// TLSCertFile and TLSKeyFile present a client certificate (mTLS) when both are set.
+	TLSCertFile string `env:"TLS_CERT_FILE" desc:"PEM client certificate for mTLS; requires TLS_KEY_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE" desc:"PEM client private key for mTLS"`
+
+	// TLSServerName overrides the name verified in the server certificate.
+	TLSServerName string `env:"TLS_SERVER_NAME" desc:"Server name to verify (default: target host)"`
+}
+
+// DefaultClientOptions returns ClientOptions with OTel enabled and the defaults noted on each field.
+func DefaultClientOptions(log zerolog.Logger) ClientOptions {
+	return ClientOptions{
+		Logger:     log,
+		EnableOTel: true,
+
+		DefaultTimeout:               10 * time.Second,
+		LoadBalancingPolicy:          "round_robin",
+		RetryMaxAttempts:             3,
+		RetryInitialBackoff:          100 * time.Millisecond,
+		RetryMaxBackoff:              time.Second,
+		MaxRecvMsgSize:               4 << 20,
+		MaxSendMsgSize:               4 << 20,
+		KeepaliveTime:                30 * time.Second,
+		KeepaliveTimeout:             10 * time.Second,
+		KeepalivePermitWithoutStream: true,
+	}
+}
+
+// ClientOptionsFromEnv returns DefaultClientOptions(log) with the fields
+// loaded from environment variables named prefix plus each field's env tag,
+// e.g. DEVICES_GRPC_DEFAULT_TIMEOUT and DEVICES_GRPC_TLS_CA_FILE for prefix
+// "DEVICES_GRPC_". Retry is left nil, so the RETRY_* variables configure the
+// service config retry policy. Every malformed or invalid variable is reported
+// in a single error.
+func ClientOptionsFromEnv(prefix string, log zerolog.Logger) (ClientOptions, error) {
+	opts := DefaultClientOptions(log)
+	if err := config.LoadPrefixed(&opts, prefix, config.EnvSource()); err != nil {
+		return ClientOptions{}, err
+	}
+	return opts, nil
+}
+
+// withDefaults fills zero transport values with the DefaultClientOptions values.
+func (o ClientOptions) withDefaults() ClientOptions {
+	def := DefaultClientOptions(o.Logger)
+	if o.LoadBalancingPolicy == "" {
+		o.LoadBalancingPolicy = def.LoadBalancingPolicy
+	}
+	if o.RetryMaxAttempts <= 0 {
+		o.RetryMaxAttempts = def.RetryMaxAttempts
+	}
+	if o.RetryInitialBackoff <= 0 {
+		o.RetryInitialBackoff = def.RetryInitialBackoff
+	}
+	if o.RetryMaxBackoff <= 0 {
+		o.RetryMaxBackoff = def.RetryMaxBackoff
+	}
+	if o.MaxRecvMsgSize <= 0 {
+		o.MaxRecvMsgSize = def.MaxRecvMsgSize
+	}
+	if o.MaxSendMsgSize <= 0 {
+		o.MaxSendMsgSize = def.MaxSendMsgSize
+	}
+	if o.KeepaliveTime <= 0 {
+		o.KeepaliveTime = def.KeepaliveTime
+	}
+	if o.KeepaliveTimeout <= 0 {
+		o.KeepaliveTimeout = def.KeepaliveTimeout
+	}
+	return o
+}
+
+// serviceConfig returns the JSON default service config: the load balancing
+// policy and, unless disabled or replaced by Retry, a retry policy for
+// UNAVAILABLE on every method.
+func (o ClientOptions) serviceConfig() string {
+	if o.ServiceConfig != "" {
+		return o.ServiceConfig
+	}
+
+	sc := map[string]any{
+		"loadBalancingConfig": []map[string]any{{o.LoadBalancingPolicy: map[string]any{}}},
+	}
+	if o.RetryMaxAttempts > 1 && o.Retry == nil {
+		sc["methodConfig"] = []map[string]any{{
+			"name": []map[string]any{{}}, // every method
+			"retryPolicy": map[string]any{
+				"maxAttempts":          o.RetryMaxAttempts,
+				"initialBackoff":       protoDuration(o.RetryInitialBackoff),
+				"maxBackoff":           protoDuration(o.RetryMaxBackoff),
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE"},
+			},
+		}}
+	}
+	out, _ := json.Marshal(sc)
+	return string(out)
+}
+
+// protoDuration formats d as a JSON google.protobuf.Duration, e.g. "0.1s".
+func protoDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// transportCredentials returns TLS credentials when any TLS field is set, and
+// insecure (plaintext) credentials otherwise.
+func (o ClientOptions) transportCredentials() (credentials.TransportCredentials, error) {
+	if !o.TLS && o.TLSCAFile == "" && o.TLSCertFile == "" && o.TLSKeyFile == "" && o.TLSServerName == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.TLSServerName,
+	}
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.TLSCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return nil, errors.New("TLSCertFile and TLSKeyFile must be set together")
+	}
+	if o.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// Dial creates a *grpc.ClientConn for target with CommonGo's client defaults:
+// logging and request-ID propagation interceptors, DefaultTimeout for unary
+// calls without a deadline, keepalive, message size limits, a default service
+// config (load balancing and UNAVAILABLE retries), optional OTel
+// instrumentation and optional TLS/mTLS.
+//
+// Like grpc.NewClient, it does not connect until the first call. extra options
+// are appended after CommonGo's options, so callers can override as needed.
+func Dial(target string, opts ClientOptions, extra ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if reflect.ValueOf(opts.Logger).IsZero() {
+		return nil, fmt.Errorf("dialing %s: ClientOptions.Logger must be set (use logger.New(...) or zerolog.Nop())", target)
+	}
+	opts = opts.withDefaults()
+	if err := config.Validate(&opts); err != nil {
+		return nil, fmt.Errorf("dialing %s: %w", target, err)
+	}
+	if opts.KeepaliveTimeout >= opts.KeepaliveTime {
+		return nil, fmt.Errorf("dialing %s: KeepaliveTimeout (%s) must be shorter than KeepaliveTime (%s)", target, opts.KeepaliveTimeout, opts.KeepaliveTime)
+	}
+
+	creds, err := opts.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s: %w", target, err)
+	}
+
+	// The default timeout and request ID are applied before logging, so logged
+	// calls carry both.
+	unary := []grpc.UnaryClientInterceptor{
+		UnaryClientTimeoutInterceptor(opts.DefaultTimeout),
+		requestid.UnaryClientInterceptor(),
+		UnaryClientLoggingInterceptor(opts.Logger, opts.Logging),
+	}
+	if opts.Retry != nil {
+		// Inside logging, so each call is logged once with its final outcome.
+		unary = append(unary, UnaryClientRetryInterceptor(*opts.Retry))
+	}
+	stream := []grpc.StreamClientInterceptor{
+		requestid.StreamClientInterceptor(),
+		StreamClientLoggingInterceptor(opts.Logger, opts.Logging),
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(opts.serviceConfig()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                opts.KeepaliveTime,
+			Timeout:             opts.KeepaliveTimeout,
+			PermitWithoutStream: opts.KeepalivePermitWithoutStream,
+		}),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(opts.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(opts.MaxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	}
+	if opts.EnableOTel {
+		dialOpts = append(dialOpts, OTELClientDialOptions()...)
+	}
+	dialOpts = append(dialOpts, extra...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s: %w", target, err)
+	}
+	return conn, nil
+}
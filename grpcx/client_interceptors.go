@@ -0,0 +1,70 @@
+package grpcx
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
+)
+
+// UnaryClientLoggingInterceptor logs outgoing unary calls using zerolog, with
+// the same levels and slow thresholds (keyed by full method) as the server interceptors.
+func UnaryClientLoggingInterceptor(log zerolog.Logger, opts LoggingOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		duration := time.Since(start)
+
+		code := status.Code(err)
+		logEvent(ctx, log, opts, method, code, duration).
+			Str("grpc_method", method).
+			Str("grpc_target", cc.Target()).
+			Str("grpc_code", code.String()).
+			Dur("duration", duration).
+			Str("request_id", requestid.FromContext(ctx)).
+			Msg("grpc call")
+
+		return err
+	}
+}
+
+// StreamClientLoggingInterceptor logs outgoing stream calls when they are
+// established. Stream completion is not observed, so streams are never logged as slow.
+func StreamClientLoggingInterceptor(log zerolog.Logger, opts LoggingOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		duration := time.Since(start)
+
+		code := status.Code(err)
+		log.WithLevel(levelForCode(code)).
+			Str("grpc_method", method).
+			Str("grpc_target", cc.Target()).
+			Bool("grpc_is_client_stream", desc.ClientStreams).
+			Bool("grpc_is_server_stream", desc.ServerStreams).
+			Str("grpc_code", code.String()).
+			Dur("duration", duration).
+			Str("request_id", requestid.FromContext(ctx)).
+			Msg("grpc stream opened")
+
+		return cs, err
+	}
+}
+
+// UnaryClientTimeoutInterceptor applies timeout to unary calls whose context
+// has no deadline. Existing deadlines are kept and propagated to the server.
+// A zero timeout disables it.
+func UnaryClientTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
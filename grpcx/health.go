@@ -8,10 +8,29 @@ import (
 )
 
 // RegisterHealth registers the standard gRPC health service and sets it to SERVING.
+// Use health.Health.DriveGRPC on the returned server to reflect dependency checks.
+// Serve flips it to NOT_SERVING on shutdown. Servers built by NewServer with
+// EnableHealth already have one; use HealthServer to get it.
 func RegisterHealth(server *grpc.Server) *health.Server {
 	hs := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(server, hs)
+	registerHealth(server, hs)
+	return hs
+}
+
+func registerHealth(server *grpc.Server, hs *health.Server) {
+	// The service metadata carries hs, so HealthServer can find it from the
+	// *grpc.Server alone.
+	desc := grpc_health_v1.Health_ServiceDesc
+	desc.Metadata = hs
+	server.RegisterService(&desc, hs)
 	hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+// HealthServer returns the health server registered on server by NewServer
+// or RegisterHealth, or nil if there is none.
+func HealthServer(server *grpc.Server) *health.Server {
+	info := server.GetServiceInfo()[grpc_health_v1.Health_ServiceDesc.ServiceName]
+	hs, _ := info.Metadata.(*health.Server)
 	return hs
 }
 
@@ -19,5 +38,3 @@ func RegisterHealth(server *grpc.Server) *health.Server {
 func RegisterReflection(server *grpc.Server) {
 	reflection.Register(server)
 }
-
-
@@ -7,30 +7,58 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
 )
 
-const requestIDHeader = "x-request-id"
+// LoggingOptions configures the logging interceptors.
+type LoggingOptions struct {
+	// SlowThreshold marks RPCs taking longer as slow: they are logged at least
+	// at Warn with slow=true. Zero disables slow detection.
+	SlowThreshold time.Duration
+
+	// SlowThresholds overrides SlowThreshold per full method (e.g. /pkg.Service/Method).
+	SlowThresholds map[string]time.Duration
+
+	// OnSlow, if set, is called for every slow RPC, e.g. to emit a metric or trace event.
+	OnSlow func(ctx context.Context, fullMethod string, duration time.Duration)
+}
+
+// slow reports whether an RPC to fullMethod taking d is slow, and the threshold applied.
+func (o LoggingOptions) slow(fullMethod string, d time.Duration) (bool, time.Duration) {
+	threshold := o.SlowThreshold
+	if t, ok := o.SlowThresholds[fullMethod]; ok {
+		threshold = t
+	}
+	return threshold > 0 && d > threshold, threshold
+}
 
 // UnaryLoggingInterceptor logs unary RPCs using zerolog.
+// Handlers get a request-scoped logger via logger.From(ctx), carrying
+// grpc_method, request_id, peer_ip and, when a span is active, trace_id and span_id.
 func UnaryLoggingInterceptor(log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return UnaryLoggingInterceptorWithOpts(log, LoggingOptions{})
+}
+
+// UnaryLoggingInterceptorWithOpts logs unary RPCs using zerolog with custom options.
+func UnaryLoggingInterceptorWithOpts(log zerolog.Logger, opts LoggingOptions) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		start := time.Now()
-		resp, err := handler(ctx, req)
+		reqLog := requestLogger(ctx, log, info.FullMethod)
+		resp, err := handler(logger.With(ctx, reqLog), req)
+		duration := time.Since(start)
 
 		code := status.Code(err)
-		ev := eventForCode(log, code)
-
-		ev.
-			Str("grpc_method", info.FullMethod).
+		logEvent(ctx, reqLog, opts, info.FullMethod, code, duration).
 			Str("grpc_code", code.String()).
-			Dur("duration", time.Since(start)).
-			Str("request_id", requestIDFromIncomingContext(ctx)).
-			Str("peer_ip", peerIP(ctx)).
+			Dur("duration", duration).
 			Msg("grpc request")
 
 		return resp, err
@@ -38,49 +66,136 @@ func UnaryLoggingInterceptor(log zerolog.Logger) grpc.UnaryServerInterceptor {
 }
 
 // StreamLoggingInterceptor logs stream RPCs using zerolog.
+// Like UnaryLoggingInterceptor, it puts a request-scoped logger into the stream context.
 func StreamLoggingInterceptor(log zerolog.Logger) grpc.StreamServerInterceptor {
+	return StreamLoggingInterceptorWithOpts(log, LoggingOptions{})
+}
+
+// StreamLoggingInterceptorWithOpts logs stream RPCs using zerolog with custom options.
+func StreamLoggingInterceptorWithOpts(log zerolog.Logger, opts LoggingOptions) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		start := time.Now()
-		err := handler(srv, ss)
-
-		code := status.Code(err)
-		ev := eventForCode(log, code)
-
-		ev.
-			Str("grpc_method", info.FullMethod).
+		ctx := ss.Context()
+		reqLog := requestLogger(ctx, log, info.FullMethod).With().
 			Bool("grpc_is_client_stream", info.IsClientStream).
 			Bool("grpc_is_server_stream", info.IsServerStream).
+			Logger()
+		err := handler(srv, &contextServerStream{ServerStream: ss, ctx: logger.With(ctx, reqLog)})
+		duration := time.Since(start)
+
+		code := status.Code(err)
+		logEvent(ctx, reqLog, opts, info.FullMethod, code, duration).
 			Str("grpc_code", code.String()).
-			Dur("duration", time.Since(start)).
-			Str("request_id", requestIDFromIncomingContext(ss.Context())).
-			Str("peer_ip", peerIP(ss.Context())).
+			Dur("duration", duration).
 			Msg("grpc request")
 
 		return err
 	}
 }
 
-func eventForCode(log zerolog.Logger, code codes.Code) *zerolog.Event {
+// requestLogger derives the request-scoped logger for an RPC.
+func requestLogger(ctx context.Context, log zerolog.Logger, fullMethod string) zerolog.Logger {
+	logCtx := log.With().
+		Str("grpc_method", fullMethod).
+		Str("request_id", requestID(ctx)).
+		Str("peer_ip", peerIP(ctx))
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logCtx = logCtx.
+			Str("trace_id", sc.TraceID().String()).
+			Str("span_id", sc.SpanID().String())
+	}
+	return logCtx.Logger()
+}
+
+// contextServerStream overrides the context of a grpc.ServerStream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// logEvent starts the completion event at the level for code, raised to Warn
+// for slow RPCs, and reports slow RPCs to opts.OnSlow.
+func logEvent(ctx context.Context, log zerolog.Logger, opts LoggingOptions, fullMethod string, code codes.Code, d time.Duration) *zerolog.Event {
+	level := levelForCode(code)
+	slow, threshold := opts.slow(fullMethod, d)
+	if !slow {
+		return log.WithLevel(level)
+	}
+
+	if opts.OnSlow != nil {
+		opts.OnSlow(ctx, fullMethod, d)
+	}
+	if level < zerolog.WarnLevel {
+		level = zerolog.WarnLevel
+	}
+	return log.WithLevel(level).Bool("slow", true).Dur("slow_threshold", threshold)
+}
+
+// codeClass groups status codes by who is likely at fault. It drives both
+// log levels and the grpc_class metric label.
+type codeClass int
+
+const (
+	classOK codeClass = iota
+	classCanceled
+	classClientError
+	classServerError
+)
+
+func (c codeClass) String() string {
+	switch c {
+	case classOK:
+		return "ok"
+	case classCanceled:
+		return "canceled"
+	case classClientError:
+		return "client_error"
+	default:
+		return "server_error"
+	}
+}
+
+func classifyCode(code codes.Code) codeClass {
 	switch {
 	case code == codes.OK:
-		return log.Info()
+		return classOK
 	case code == codes.Canceled || code == codes.DeadlineExceeded:
-		return log.Warn()
+		return classCanceled
 	case code == codes.InvalidArgument || code == codes.NotFound || code == codes.AlreadyExists ||
 		code == codes.PermissionDenied || code == codes.Unauthenticated || code == codes.FailedPrecondition ||
 		code == codes.ResourceExhausted || code == codes.Aborted || code == codes.OutOfRange:
-		return log.Warn()
+		return classClientError
 	default:
-		return log.Error()
+		return classServerError
 	}
 }
 
-func requestIDFromIncomingContext(ctx context.Context) string {
+func levelForCode(code codes.Code) zerolog.Level {
+	switch classifyCode(code) {
+	case classOK:
+		return zerolog.InfoLevel
+	case classCanceled, classClientError:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+// requestID returns the request ID set by requestid.UnaryServerInterceptor,
+// falling back to the incoming x-request-id metadata.
+func requestID(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return id
+	}
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return ""
 	}
-	vals := md.Get(requestIDHeader)
+	vals := md.Get(requestid.MetadataKey)
 	if len(vals) == 0 {
 		return ""
 	}
@@ -0,0 +1,468 @@
+package grpcx
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// JWTOptions configures JWTAuthenticator. At least one of JWKSFile and Keys must be set.
+type JWTOptions struct {
+	// JWKSFile is a local JSON Web Key Set (e.g. mounted from a secret). It is
+	// re-read when it changes, checked at most once per JWKSReloadInterval.
+	JWKSFile string
+
+	// JWKSReloadInterval is how often JWKSFile is checked for changes (default: 1m).
+	JWKSReloadInterval time.Duration
+
+	// Keys are static verification keys by key ID ("" matches tokens without
+	// a kid): []byte for HS256/384/512, *rsa.PublicKey for RS and PS
+	// algorithms, *ecdsa.PublicKey for ES256/384/512. HMAC keys must be at
+	// least 32 bytes, and only verify algorithms whose hash is no longer than
+	// the key (a 32-byte key is used for HS256 only).
+	Keys map[string]any
+
+	// Algorithms restricts the accepted "alg" values (default: every supported
+	// algorithm). A key only ever verifies algorithms of its own type.
+	Algorithms []string
+
+	// Issuer, if set, must equal the "iss" claim.
+	Issuer string
+
+	// Audience, if set, must be one of the "aud" claim values.
+	Audience string
+
+	// Leeway tolerates clock skew when checking "exp" and "nbf" (default: 1m).
+	Leeway time.Duration
+}
+
+// jwtAlgorithms maps each supported "alg" to its hash.
+var jwtAlgorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// JWTAuthenticator authenticates "authorization: Bearer <jwt>" metadata.
+// The token must carry an unexpired "exp"; scopes are read from the
+// space-separated "scope" claim or the "scp" claim (string or array).
+func JWTAuthenticator(opts JWTOptions) (Authenticator, error) {
+	if opts.JWKSFile == "" && len(opts.Keys) == 0 {
+		return nil, errors.New("jwt authenticator: JWKSFile or Keys must be set")
+	}
+	if opts.Leeway <= 0 {
+		opts.Leeway = time.Minute
+	}
+	if opts.JWKSReloadInterval <= 0 {
+		opts.JWKSReloadInterval = time.Minute
+	}
+	for _, alg := range opts.Algorithms {
+		if _, ok := jwtAlgorithms[alg]; !ok {
+			return nil, fmt.Errorf("jwt authenticator: unsupported algorithm %q", alg)
+		}
+	}
+
+	a := &jwtAuthenticator{opts: opts}
+	for kid, key := range opts.Keys {
+		switch k := key.(type) {
+		case []byte:
+			if err := checkHMACKey(k, ""); err != nil {
+				return nil, fmt.Errorf("jwt authenticator: kid %q: %w", kid, err)
+			}
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("jwt authenticator: unsupported key type %T for kid %q", key, kid)
+		}
+		a.static = append(a.static, jwk{kid: kid, key: key})
+	}
+	if opts.JWKSFile != "" {
+		a.jwks = &jwksFile{path: opts.JWKSFile, interval: opts.JWKSReloadInterval}
+		if err := a.jwks.reload(); err != nil {
+			return nil, fmt.Errorf("jwt authenticator: %w", err)
+		}
+	}
+	return a, nil
+}
+
+type jwtAuthenticator struct {
+	opts   JWTOptions
+	static []jwk
+	jwks   *jwksFile
+}
+
+func (a *jwtAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, v := range md.Get("authorization") {
+		if scheme, t, ok := strings.Cut(v, " "); ok && strings.EqualFold(scheme, "bearer") {
+			token = strings.TrimSpace(t)
+			break
+		}
+	}
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+
+	p := &Principal{Method: "jwt", Claims: claims}
+	p.Subject, _ = claims["sub"].(string)
+	p.Issuer, _ = claims["iss"].(string)
+	p.Audience = stringList(claims["aud"])
+	if scope, ok := claims["scope"].(string); ok {
+		p.Scopes = strings.Fields(scope)
+	} else if scp, ok := claims["scp"].(string); ok {
+		p.Scopes = strings.Fields(scp)
+	} else {
+		p.Scopes = stringList(claims["scp"])
+	}
+	return p, nil
+}
+
+// verify checks the signature and registered claims of a compact JWS and returns its claims.
+func (a *jwtAuthenticator) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg  string          `json:"alg"`
+		Kid  string          `json:"kid"`
+		Crit json.RawMessage `json:"crit"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+	// No JWS extensions are supported, so any critical one must be rejected (RFC 7515 §4.1.11).
+	if header.Crit != nil {
+		return nil, errors.New("unsupported crit header")
+	}
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok || (len(a.opts.Algorithms) > 0 && !slices.Contains(a.opts.Algorithms, header.Alg)) {
+		return nil, fmt.Errorf("algorithm %q not allowed", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	keys := a.static
+	if a.jwks != nil {
+		keys = append(slices.Clip(keys), a.jwks.keys()...)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range keys {
+		if k.kid != header.Kid || (k.alg != "" && k.alg != header.Alg) {
+			continue
+		}
+		if verifySignature(header.Alg, hash, k.key, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("signature verification failed")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decoding claims: %w", err)
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *jwtAuthenticator) checkClaims(claims map[string]any) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(a.opts.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.opts.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not valid yet")
+	}
+	if a.opts.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.opts.Issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if a.opts.Audience != "" && !slices.Contains(stringList(claims["aud"]), a.opts.Audience) {
+		return fmt.Errorf("audience %q not accepted", a.opts.Audience)
+	}
+	return nil
+}
+
+// checkHMACKey reports an HMAC key shorter than the hash of alg, or of
+// HS256 if alg is empty.
+func checkHMACKey(key []byte, alg string) error {
+	size := crypto.SHA256.Size()
+	if hash, ok := jwtAlgorithms[alg]; ok && strings.HasPrefix(alg, "HS") {
+		size = hash.Size()
+	}
+	switch {
+	case len(key) == 0:
+		return errors.New("empty HMAC key")
+	case len(key) < size:
+		return fmt.Errorf("HMAC key is %d bytes, want at least %d", len(key), size)
+	}
+	return nil
+}
+
+// verifySignature verifies sig over signed with key, only if the key type
+// matches alg. HMAC keys shorter than the hash of alg never verify.
+func verifySignature(alg string, hash crypto.Hash, key any, signed, sig []byte) bool {
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") || len(k) < hash.Size() {
+			return false
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write(signed)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case *rsa.PublicKey:
+		switch {
+		case strings.HasPrefix(alg, "RS"):
+			return rsa.VerifyPKCS1v15(k, hash, digest, sig) == nil
+		case strings.HasPrefix(alg, "PS"):
+			return rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+		return false
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return false
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size || hashForCurve(k.Curve) != hash {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	default:
+		return false
+	}
+}
+
+func hashForCurve(c elliptic.Curve) crypto.Hash {
+	switch c {
+	case elliptic.P256():
+		return crypto.SHA256
+	case elliptic.P384():
+		return crypto.SHA384
+	case elliptic.P521():
+		return crypto.SHA512
+	default:
+		return 0
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// stringList returns a claim that is either a string or an array of strings as a slice.
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// jwk is a verification key with its optional key ID and algorithm.
+type jwk struct {
+	kid string
+	alg string
+	key any
+}
+
+// jwksFile serves the keys of a JWKS file, re-reading it when it changes.
+// Changes are checked at most once per interval, on verification.
+type jwksFile struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	set       []jwk
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func (f *jwksFile) keys() []jwk {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.lastCheck) >= f.interval {
+		// Keep serving the previous keys if the file is briefly unreadable or invalid.
+		_ = f.reloadLocked()
+	}
+	return f.set
+}
+
+func (f *jwksFile) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reloadLocked()
+}
+
+func (f *jwksFile) reloadLocked() error {
+	f.lastCheck = time.Now()
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("reading JWKS file: %w", err)
+	}
+	if f.set != nil && fi.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("reading JWKS file: %w", err)
+	}
+	set, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parsing JWKS file %s: %w", f.path, err)
+	}
+	f.set, f.modTime = set, fi.ModTime()
+	return nil
+}
+
+// parseJWKS parses the RSA, EC and symmetric signing keys of a JSON Web Key Set.
+func parseJWKS(data []byte) ([]jwk, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	set := make([]jwk, 0, len(doc.Keys))
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key any
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = parseRSAKey(k.N, k.E)
+		case "EC":
+			key, err = parseECKey(k.Crv, k.X, k.Y)
+		case "oct":
+			key, err = parseOctKey(k.K, k.Alg)
+		default:
+			err = fmt.Errorf("unsupported kty %q", k.Kty)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d (kid %q): %w", i, k.Kid, err)
+		}
+		set = append(set, jwk{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	return set, nil
+}
+
+func parseRSAKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("decoding n: %w", err)
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("decoding e: %w", err)
+	}
+	exp := new(big.Int).SetBytes(eb)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(exp.Int64())}, nil
+}
+
+func parseECKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported crv %q", crv)
+	}
+	xb, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil {
+		return nil, fmt.Errorf("decoding x: %w", err)
+	}
+	yb, err := base64.RawURLEncoding.DecodeString(y)
+	if err != nil {
+		return nil, fmt.Errorf("decoding y: %w", err)
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xb), Y: new(big.Int).SetBytes(yb)}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return key, nil
+}
+
+func parseOctKey(k, alg string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(k)
+	if err != nil {
+		return nil, fmt.Errorf("decoding k: %w", err)
+	}
+	if err := checkHMACKey(key, alg); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
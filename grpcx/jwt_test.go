@@ -0,0 +1,345 @@
+package grpcx
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type testKeys struct {
+	hs  []byte
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{hs: []byte("0123456789abcdef0123456789abcdef"), rsa: rsaKey, ec: ecKey}
+}
+
+// signer returns the signature of signed.
+type signer func(t *testing.T, signed []byte) []byte
+
+func hsSigner(secret []byte) signer {
+	return func(t *testing.T, signed []byte) []byte {
+		mac := hmac.New(crypto.SHA256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+}
+
+func rsSigner(key *rsa.PrivateKey) signer {
+	return func(t *testing.T, signed []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sha256Digest(signed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+func psSigner(key *rsa.PrivateKey) signer {
+	return func(t *testing.T, signed []byte) []byte {
+		sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, sha256Digest(signed), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+func esSigner(key *ecdsa.PrivateKey) signer {
+	return func(t *testing.T, signed []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, key, sha256Digest(signed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	}
+}
+
+func sha256Digest(b []byte) []byte {
+	h := crypto.SHA256.New()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func makeToken(t *testing.T, header, claims map[string]any, sign signer) string {
+	t.Helper()
+	seg := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := seg(header) + "." + seg(claims)
+	var sig []byte
+	if sign != nil {
+		sig = sign(t, []byte(signed))
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	keys := newTestKeys(t)
+	rsaPub, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := JWTAuthenticator(JWTOptions{
+		Keys: map[string]any{
+			"hs": keys.hs,
+			"rs": &keys.rsa.PublicKey,
+			"es": &keys.ec.PublicKey,
+		},
+		Issuer:   "https://issuer.example",
+		Audience: "devices",
+		Leeway:   time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	valid := func() map[string]any {
+		return map[string]any{
+			"sub":   "user-1",
+			"iss":   "https://issuer.example",
+			"aud":   []string{"devices", "billing"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "devices:read devices:write",
+		}
+	}
+	with := func(key string, value any) map[string]any {
+		c := valid()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+	truncate := func(s signer) signer {
+		return func(t *testing.T, signed []byte) []byte {
+			sig := s(t, signed)
+			return sig[:len(sig)-1]
+		}
+	}
+
+	tests := []struct {
+		name    string
+		header  map[string]any
+		claims  map[string]any
+		sign    signer
+		wantErr bool
+	}{
+		{name: "HS256", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: valid(), sign: hsSigner(keys.hs)},
+		{name: "RS256", header: map[string]any{"alg": "RS256", "kid": "rs"}, claims: valid(), sign: rsSigner(keys.rsa)},
+		{name: "PS256", header: map[string]any{"alg": "PS256", "kid": "rs"}, claims: valid(), sign: psSigner(keys.rsa)},
+		{name: "ES256", header: map[string]any{"alg": "ES256", "kid": "es"}, claims: valid(), sign: esSigner(keys.ec)},
+		{name: "nbf in the past", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("nbf", now.Add(-time.Minute).Unix()), sign: hsSigner(keys.hs)},
+
+		// An HS token "signed" with the RSA public key must not verify against it.
+		{name: "HS token with RSA key", header: map[string]any{"alg": "HS256", "kid": "rs"}, claims: valid(), sign: hsSigner(rsaPub), wantErr: true},
+		{name: "RS token with HMAC key", header: map[string]any{"alg": "RS256", "kid": "hs"}, claims: valid(), sign: rsSigner(keys.rsa), wantErr: true},
+		{name: "alg none", header: map[string]any{"alg": "none", "kid": "hs"}, claims: valid(), wantErr: true},
+		{name: "alg none signed", header: map[string]any{"alg": "none", "kid": "hs"}, claims: valid(), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "kid mismatch", header: map[string]any{"alg": "HS256", "kid": "other"}, claims: valid(), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "kid missing", header: map[string]any{"alg": "HS256"}, claims: valid(), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "wrong HMAC secret", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: valid(), sign: hsSigner([]byte("wrong")), wantErr: true},
+		{name: "ES signature too short", header: map[string]any{"alg": "ES256", "kid": "es"}, claims: valid(), sign: truncate(esSigner(keys.ec)), wantErr: true},
+		{name: "ES384 with P-256 key", header: map[string]any{"alg": "ES384", "kid": "es"}, claims: valid(), sign: esSigner(keys.ec), wantErr: true},
+		{name: "crit header", header: map[string]any{"alg": "HS256", "kid": "hs", "crit": []string{"exp"}, "exp": 1}, claims: valid(), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "expired", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("exp", now.Add(-time.Minute).Unix()), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "exp missing", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("exp", nil), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "nbf in the future", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("nbf", now.Add(time.Minute).Unix()), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "wrong issuer", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("iss", "https://evil.example"), sign: hsSigner(keys.hs), wantErr: true},
+		{name: "wrong audience", header: map[string]any{"alg": "HS256", "kid": "hs"}, claims: with("aud", "billing"), sign: hsSigner(keys.hs), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := makeToken(t, tt.header, tt.claims, tt.sign)
+			p, err := auth.Authenticate(bearerContext(token))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Authenticate() succeeded, want error")
+				}
+				if errors.Is(err, ErrNoCredentials) {
+					t.Fatalf("Authenticate() = %v, want an invalid credentials error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if p.Subject != "user-1" || p.Method != "jwt" || !p.HasScope("devices:write") {
+				t.Errorf("Authenticate() = %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorNoCredentials(t *testing.T) {
+	auth, err := JWTAuthenticator(JWTOptions{Keys: map[string]any{"": newTestKeys(t).hs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, md := range []metadata.MD{nil, metadata.Pairs("authorization", "Basic dXNlcjpwYXNz")} {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		if _, err := auth.Authenticate(ctx); !errors.Is(err, ErrNoCredentials) {
+			t.Errorf("Authenticate(%v) error = %v, want ErrNoCredentials", md, err)
+		}
+	}
+}
+
+func TestJWTAuthenticatorAlgorithms(t *testing.T) {
+	keys := newTestKeys(t)
+	auth, err := JWTAuthenticator(JWTOptions{
+		Keys:       map[string]any{"hs": keys.hs},
+		Algorithms: []string{"HS512"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := makeToken(t, map[string]any{"alg": "HS256", "kid": "hs"},
+		map[string]any{"exp": time.Now().Add(time.Hour).Unix()}, hsSigner(keys.hs))
+	if _, err := auth.Authenticate(bearerContext(token)); err == nil {
+		t.Fatal("Authenticate() accepted an algorithm outside Algorithms")
+	}
+
+	if _, err := JWTAuthenticator(JWTOptions{Keys: map[string]any{"hs": keys.hs}, Algorithms: []string{"none"}}); err == nil {
+		t.Fatal("JWTAuthenticator() accepted algorithm none")
+	}
+}
+
+func TestJWTAuthenticatorJWKSFile(t *testing.T) {
+	keys := newTestKeys(t)
+	pub := keys.ec.PublicKey
+	jwks := map[string]any{"keys": []map[string]any{{
+		"kty": "EC",
+		"kid": "es",
+		"alg": "ES256",
+		"use": "sig",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+	}}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := JWTAuthenticator(JWTOptions{JWKSFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]any{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()}
+
+	token := makeToken(t, map[string]any{"alg": "ES256", "kid": "es"}, claims, esSigner(keys.ec))
+	if _, err := auth.Authenticate(bearerContext(token)); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	// The JWK pins ES256, so an HS token using the key's kid must fail.
+	token = makeToken(t, map[string]any{"alg": "HS256", "kid": "es"}, claims, hsSigner(data))
+	if _, err := auth.Authenticate(bearerContext(token)); err == nil {
+		t.Fatal("Authenticate() accepted an HS token for an ES key")
+	}
+}
+
+func TestJWTAuthenticatorHMACKeyLength(t *testing.T) {
+	oct := func(alg string, key []byte) string {
+		data, err := json.Marshal(map[string]any{"keys": []map[string]any{{
+			"kty": "oct", "kid": "hs", "alg": alg, "k": base64.RawURLEncoding.EncodeToString(key),
+		}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	key32 := []byte("0123456789abcdef0123456789abcdef")
+	key64 := append(append([]byte{}, key32...), key32...)
+
+	tests := []struct {
+		name    string
+		opts    JWTOptions
+		wantErr bool
+	}{
+		{name: "static empty", opts: JWTOptions{Keys: map[string]any{"hs": []byte{}}}, wantErr: true},
+		{name: "static nil", opts: JWTOptions{Keys: map[string]any{"hs": []byte(nil)}}, wantErr: true},
+		{name: "static short", opts: JWTOptions{Keys: map[string]any{"hs": []byte("secret")}}, wantErr: true},
+		{name: "static 31 bytes", opts: JWTOptions{Keys: map[string]any{"hs": key32[:31]}}, wantErr: true},
+		{name: "static 32 bytes", opts: JWTOptions{Keys: map[string]any{"hs": key32}}},
+		{name: "JWKS empty", opts: JWTOptions{JWKSFile: oct("", nil)}, wantErr: true},
+		{name: "JWKS short", opts: JWTOptions{JWKSFile: oct("HS256", []byte("secret"))}, wantErr: true},
+		{name: "JWKS shorter than its alg hash", opts: JWTOptions{JWKSFile: oct("HS512", key32)}, wantErr: true},
+		{name: "JWKS 32 bytes", opts: JWTOptions{JWKSFile: oct("HS256", key32)}},
+		{name: "JWKS HS512 64 bytes", opts: JWTOptions{JWKSFile: oct("HS512", key64)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := JWTAuthenticator(tt.opts)
+			if tt.wantErr && err == nil {
+				t.Fatal("JWTAuthenticator() succeeded, want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("JWTAuthenticator() error = %v", err)
+			}
+		})
+	}
+
+	// A static key is only used for algorithms whose hash fits in it.
+	auth, err := JWTAuthenticator(JWTOptions{Keys: map[string]any{"hs": key32}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs512 := func(t *testing.T, signed []byte) []byte {
+		mac := hmac.New(crypto.SHA512.New, key32)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+	token := makeToken(t, map[string]any{"alg": "HS512", "kid": "hs"},
+		map[string]any{"exp": time.Now().Add(time.Hour).Unix()}, hs512)
+	if _, err := auth.Authenticate(bearerContext(token)); err == nil {
+		t.Fatal("Authenticate() accepted HS512 with a 32-byte key")
+	}
+}
@@ -0,0 +1,134 @@
+package grpcx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/nikolapavicevic-001/CommonGo/internal/promutil"
+)
+
+type serverMetrics struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	received *prometheus.CounterVec
+	sent     *prometheus.CounterVec
+}
+
+// newServerMetrics registers the server collectors with reg (default:
+// prometheus.DefaultRegisterer), reusing them if another server already did.
+func newServerMetrics(reg prometheus.Registerer) *serverMetrics {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	methodLabels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	codeLabels := append(methodLabels[:len(methodLabels):len(methodLabels)], "grpc_code", "grpc_class")
+
+	return &serverMetrics{
+		handled: promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, by status code.",
+		}, codeLabels)),
+		duration: promutil.Register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "RPC handling time on the server in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, methodLabels)),
+		received: promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total number of stream messages received from clients.",
+		}, methodLabels)),
+		sent: promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total number of stream messages sent to clients.",
+		}, methodLabels)),
+	}
+}
+
+func (m *serverMetrics) observe(rpcType, fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	code := status.Code(err)
+	m.handled.WithLabelValues(rpcType, service, method, code.String(), classifyCode(code).String()).Inc()
+	m.duration.WithLabelValues(rpcType, service, method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryMetricsInterceptor records Prometheus metrics for unary RPCs:
+// grpc_server_handled_total by service, method, code and class (ok, canceled,
+// client_error, server_error; the same grouping used for log levels), and the
+// grpc_server_handling_seconds histogram by service and method.
+// A nil reg uses prometheus.DefaultRegisterer.
+func UnaryMetricsInterceptor(reg prometheus.Registerer) grpc.UnaryServerInterceptor {
+	m := newServerMetrics(reg)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe("unary", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamMetricsInterceptor records the same metrics as UnaryMetricsInterceptor
+// for stream RPCs, plus grpc_server_msg_received_total and grpc_server_msg_sent_total.
+func StreamMetricsInterceptor(reg prometheus.Registerer) grpc.StreamServerInterceptor {
+	m := newServerMetrics(reg)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		service, method := splitMethod(info.FullMethod)
+		rpcType := streamType(info)
+		ms := &metricsServerStream{
+			ServerStream: ss,
+			received:     m.received.WithLabelValues(rpcType, service, method),
+			sent:         m.sent.WithLabelValues(rpcType, service, method),
+		}
+
+		start := time.Now()
+		err := handler(srv, ms)
+		m.observe(rpcType, info.FullMethod, start, err)
+		return err
+	}
+}
+
+type metricsServerStream struct {
+	grpc.ServerStream
+	received prometheus.Counter
+	sent     prometheus.Counter
+}
+
+func (s *metricsServerStream) SendMsg(msg any) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *metricsServerStream) RecvMsg(msg any) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.received.Inc()
+	}
+	return err
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+// splitMethod splits "/pkg.Service/Method" into service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -15,4 +15,9 @@ func OTELServerOptions() []grpc.ServerOption {
 	}
 }
 
-
+// OTELClientDialOptions returns gRPC dial options to enable OpenTelemetry instrumentation.
+func OTELClientDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+}
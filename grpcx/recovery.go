@@ -0,0 +1,92 @@
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nikolapavicevic-001/CommonGo/internal/promutil"
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+)
+
+// RecoveryOptions configures the recovery interceptors.
+type RecoveryOptions struct {
+	// Handler converts a recovered panic value into the error returned to the
+	// client. The default returns codes.Internal without exposing the panic value.
+	Handler func(ctx context.Context, p any) error
+
+	// Registerer receives the grpc_server_panics_total counter (default: prometheus.DefaultRegisterer).
+	Registerer prometheus.Registerer
+}
+
+// UnaryRecoveryInterceptor turns panics in unary handlers into errors
+// (codes.Internal by default) instead of crashing the process. The panic and
+// its stack trace are logged through logger.From(ctx) and counted in
+// grpc_server_panics_total by service and method.
+func UnaryRecoveryInterceptor(opts RecoveryOptions) grpc.UnaryServerInterceptor {
+	r := newRecoverer(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = r.recovered(ctx, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor is the stream counterpart of UnaryRecoveryInterceptor.
+func StreamRecoveryInterceptor(opts RecoveryOptions) grpc.StreamServerInterceptor {
+	r := newRecoverer(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = r.recovered(ss.Context(), info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+type recoverer struct {
+	handler func(ctx context.Context, p any) error
+	panics  *prometheus.CounterVec
+}
+
+func newRecoverer(opts RecoveryOptions) *recoverer {
+	reg := opts.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	handler := opts.Handler
+	if handler == nil {
+		handler = func(context.Context, any) error {
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+	return &recoverer{
+		handler: handler,
+		panics: promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_panics_total",
+			Help: "Total number of panics recovered in RPC handlers.",
+		}, []string{"grpc_service", "grpc_method"})),
+	}
+}
+
+func (r *recoverer) recovered(ctx context.Context, fullMethod string, p any) error {
+	service, method := splitMethod(fullMethod)
+	r.panics.WithLabelValues(service, method).Inc()
+
+	log := logger.From(ctx)
+	log.Error().
+		Str("panic", fmt.Sprint(p)).
+		Str("stack", string(debug.Stack())).
+		Msg("grpc handler panicked")
+
+	return r.handler(ctx, p)
+}
@@ -0,0 +1,345 @@
+package grpcx
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nikolapavicevic-001/CommonGo/internal/promutil"
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+)
+
+// RetryPolicy controls how a unary method is retried. Zero fields use the
+// defaults noted on each field, except Jitter, where zero disables jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first (default: 3; 1 disables retries).
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry (default: 100ms).
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts (default: 2s).
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each attempt (default: 2).
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to ± this fraction (0: no jitter;
+	// negative: 0.2, the DefaultRetryPolicy value).
+	Jitter float64
+
+	// Codes are the status codes worth retrying (default: Unavailable, ResourceExhausted).
+	Codes []codes.Code
+
+	// PerAttemptTimeout bounds each attempt, within the call deadline (0: none).
+	PerAttemptTimeout time.Duration
+
+	// HedgingDelay, if set, enables hedging: when an attempt has not finished
+	// after this delay, another is started concurrently and the first success
+	// wins. Only use it for idempotent methods. Replies must be proto messages;
+	// other replies are retried sequentially instead.
+	HedgingDelay time.Duration
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with every default applied.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Codes:          []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = def.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = def.Multiplier
+	}
+	if p.Jitter < 0 {
+		p.Jitter = def.Jitter
+	}
+	if p.Codes == nil {
+		p.Codes = def.Codes
+	}
+	return p
+}
+
+func (p RetryPolicy) retryable(code codes.Code) bool {
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the jittered delay after the given (1-based) attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	d = math.Min(d, float64(p.MaxBackoff))
+	d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	return time.Duration(d)
+}
+
+// RetryOptions configures UnaryClientRetryInterceptor.
+type RetryOptions struct {
+	// Default applies to methods without an entry in Methods.
+	Default RetryPolicy
+
+	// Methods overrides Default by full method ("/pkg.Service/Method") or, for
+	// a whole service, by "/pkg.Service/".
+	Methods map[string]RetryPolicy
+
+	// Registerer receives the grpc_client_attempts_total counter (default: prometheus.DefaultRegisterer).
+	Registerer prometheus.Registerer
+}
+
+// UnaryClientRetryInterceptor retries unary calls failing with one of the
+// policy's codes, with exponential backoff and jitter, or hedges them when
+// HedgingDelay is set. A retry is never started if its backoff would outlast
+// the call deadline; the last error is returned instead.
+//
+// Each failed attempt that is retried, and each hedged attempt started, is
+// logged through logger.From(ctx), and every attempt is counted in
+// grpc_client_attempts_total by service, method, code and kind (first, retry
+// or hedge). Hedged attempts abandoned after another one won count as Canceled.
+func UnaryClientRetryInterceptor(opts RetryOptions) grpc.UnaryClientInterceptor {
+	r := &retrier{
+		def:     opts.Default.withDefaults(),
+		methods: make(map[string]RetryPolicy, len(opts.Methods)),
+	}
+	for m, p := range opts.Methods {
+		r.methods[m] = p.withDefaults()
+	}
+	reg := opts.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	r.attempts = promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_attempts_total",
+		Help: "Total number of client RPC attempts, including retries and hedges.",
+	}, []string{"grpc_service", "grpc_method", "grpc_code", "kind"}))
+
+	return r.intercept
+}
+
+type retrier struct {
+	def      RetryPolicy
+	methods  map[string]RetryPolicy
+	attempts *prometheus.CounterVec
+}
+
+func (r *retrier) policy(method string) RetryPolicy {
+	if p, ok := r.methods[method]; ok {
+		return p
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		if p, ok := r.methods[method[:i+1]]; ok {
+			return p
+		}
+	}
+	return r.def
+}
+
+func (r *retrier) intercept(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+	p := r.policy(method)
+	if p.MaxAttempts <= 1 {
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+	if msg, ok := reply.(proto.Message); ok && p.HedgingDelay > 0 {
+		return r.hedge(ctx, p, method, req, msg, cc, invoker, callOpts)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.attempt(ctx, p, method, attempt, false, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		})
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(status.Code(err)) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		log := logger.From(ctx)
+		log.Warn().
+			Err(err).
+			Str("grpc_method", method).
+			Int("attempt", attempt).
+			Dur("backoff", delay).
+			Msg("retrying grpc call")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// hedge runs up to p.MaxAttempts concurrent attempts, starting a new one every
+// HedgingDelay or as soon as one fails with a retryable code, and copies the
+// first successful reply into reply. A non-retryable failure ends the call.
+// Header, Trailer and Peer call options are only filled from the attempt whose
+// result is returned.
+func (r *retrier) hedge(ctx context.Context, p RetryPolicy, method string, req any, reply proto.Message, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts []grpc.CallOption) error {
+	// Cancelling ctx on return abandons the attempts still in flight.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply  proto.Message
+		err    error
+		commit func()
+	}
+	results := make(chan result, p.MaxAttempts)
+	launched, pending := 0, 0
+	launch := func() {
+		launched++
+		pending++
+		attempt, out := launched, proto.Clone(reply)
+		proto.Reset(out)
+		opts, commit := privateCallOptions(callOpts)
+		go func() {
+			err := r.attempt(ctx, p, method, attempt, true, func(ctx context.Context) error {
+				return invoker(ctx, method, req, out, cc, opts...)
+			})
+			results <- result{reply: out, err: err, commit: commit}
+		}()
+	}
+
+	launch()
+	hedgeTimer := time.After(p.HedgingDelay)
+	var last result
+	for {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				res.commit()
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply)
+				return nil
+			}
+			last = res
+			if !p.retryable(status.Code(res.err)) {
+				res.commit()
+				return res.err
+			}
+			if launched < p.MaxAttempts {
+				logHedge(ctx, method, launched, res.err)
+				launch()
+				hedgeTimer = time.After(p.HedgingDelay)
+			} else if pending == 0 {
+				res.commit()
+				return res.err
+			}
+		case <-hedgeTimer:
+			if launched < p.MaxAttempts {
+				logHedge(ctx, method, launched, nil)
+				launch()
+				hedgeTimer = time.After(p.HedgingDelay)
+			}
+		case <-ctx.Done():
+			if last.err != nil {
+				last.commit()
+				return last.err
+			}
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// logHedge logs that another attempt is started after attempt, either
+// because it failed with err or, for a nil err, because HedgingDelay passed.
+func logHedge(ctx context.Context, method string, attempt int, err error) {
+	level := zerolog.InfoLevel
+	if err != nil {
+		level = zerolog.WarnLevel
+	}
+	log := logger.From(ctx)
+	log.WithLevel(level).
+		Err(err).
+		Str("grpc_method", method).
+		Int("attempt", attempt).
+		Msg("hedging grpc call")
+}
+
+// privateCallOptions returns callOpts with the Header, Trailer and Peer
+// options pointed at values private to one attempt, so concurrent attempts
+// don't write through the caller's pointers. commit copies the attempt's
+// values to the caller once the attempt has finished.
+func privateCallOptions(callOpts []grpc.CallOption) (opts []grpc.CallOption, commit func()) {
+	opts = make([]grpc.CallOption, 0, len(callOpts))
+	var commits []func()
+	for _, o := range callOpts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			md := new(metadata.MD)
+			opts = append(opts, grpc.Header(md))
+			commits = append(commits, func() { *o.HeaderAddr = *md })
+		case grpc.TrailerCallOption:
+			md := new(metadata.MD)
+			opts = append(opts, grpc.Trailer(md))
+			commits = append(commits, func() { *o.TrailerAddr = *md })
+		case grpc.PeerCallOption:
+			p := new(peer.Peer)
+			opts = append(opts, grpc.Peer(p))
+			commits = append(commits, func() { *o.PeerAddr = *p })
+		default:
+			opts = append(opts, o)
+		}
+	}
+	return opts, func() {
+		for _, c := range commits {
+			c()
+		}
+	}
+}
+
+// attempt runs one attempt within PerAttemptTimeout and records it.
+func (r *retrier) attempt(ctx context.Context, p RetryPolicy, method string, attempt int, hedged bool, call func(context.Context) error) error {
+	if p.PerAttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.PerAttemptTimeout)
+		defer cancel()
+	}
+	err := call(ctx)
+
+	kind := "first"
+	switch {
+	case attempt > 1 && hedged:
+		kind = "hedge"
+	case attempt > 1:
+		kind = "retry"
+	}
+	service, name := splitMethod(method)
+	r.attempts.WithLabelValues(service, name, status.Code(err).String(), kind).Inc()
+	return err
+}
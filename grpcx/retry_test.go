@@ -0,0 +1,296 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// scriptedHealth answers Check with the error handle returns for each call (1-based).
+type scriptedHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls  atomic.Int32
+	handle func(ctx context.Context, call int32) error
+}
+
+func (s *scriptedHealth) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := s.handle(ctx, s.calls.Add(1)); err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// failFirst fails the first n calls with code.
+func failFirst(n int32, code codes.Code) func(context.Context, int32) error {
+	return func(_ context.Context, call int32) error {
+		if call <= n {
+			return status.Error(code, "scripted failure")
+		}
+		return nil
+	}
+}
+
+// dialRetry serves h over bufconn and returns a client using the retry interceptor.
+func dialRetry(t *testing.T, h *scriptedHealth, policy RetryPolicy, reg *prometheus.Registry) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, h)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientRetryInterceptor(RetryOptions{Default: policy, Registerer: reg})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return grpc_health_v1.NewHealthClient(cc)
+}
+
+// attemptCounts returns grpc_client_attempts_total by "kind/code".
+func attemptCounts(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]float64)
+	for _, mf := range families {
+		if mf.GetName() != "grpc_client_attempts_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			var kind, code string
+			for _, l := range m.GetLabel() {
+				switch l.GetName() {
+				case "kind":
+					kind = l.GetValue()
+				case "grpc_code":
+					code = l.GetValue()
+				}
+			}
+			counts[kind+"/"+code] += m.GetCounter().GetValue()
+		}
+	}
+	return counts
+}
+
+func TestUnaryClientRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		handle     func(context.Context, int32) error
+		timeout    time.Duration
+		wantCode   codes.Code
+		wantCalls  int32
+		minElapsed time.Duration
+		maxElapsed time.Duration
+		wantCounts map[string]float64
+	}{
+		{
+			name:       "retries until success with exponential backoff",
+			policy:     RetryPolicy{MaxAttempts: 3, InitialBackoff: 40 * time.Millisecond, Multiplier: 2},
+			handle:     failFirst(2, codes.Unavailable),
+			wantCode:   codes.OK,
+			wantCalls:  3,
+			minElapsed: 120 * time.Millisecond, // 40ms + 80ms
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/Unavailable": 1, "retry/Unavailable": 1, "retry/OK": 1},
+		},
+		{
+			name:       "backoff is capped by MaxBackoff",
+			policy:     RetryPolicy{MaxAttempts: 3, InitialBackoff: 40 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 10},
+			handle:     failFirst(2, codes.Unavailable),
+			wantCode:   codes.OK,
+			wantCalls:  3,
+			minElapsed: 90 * time.Millisecond, // 40ms + 50ms instead of 40ms + 400ms
+			maxElapsed: 350 * time.Millisecond,
+		},
+		{
+			name:       "gives up after MaxAttempts",
+			policy:     RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			handle:     failFirst(10, codes.Unavailable),
+			wantCode:   codes.Unavailable,
+			wantCalls:  3,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/Unavailable": 1, "retry/Unavailable": 2},
+		},
+		{
+			name:       "does not retry other codes",
+			policy:     RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			handle:     failFirst(10, codes.InvalidArgument),
+			wantCode:   codes.InvalidArgument,
+			wantCalls:  1,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/InvalidArgument": 1},
+		},
+		{
+			name:       "does not start a retry that would outlast the deadline",
+			policy:     RetryPolicy{MaxAttempts: 3, InitialBackoff: 5 * time.Second},
+			handle:     failFirst(10, codes.Unavailable),
+			timeout:    time.Second,
+			wantCode:   codes.Unavailable,
+			wantCalls:  1,
+			maxElapsed: 500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			h := &scriptedHealth{handle: tt.handle}
+			client := dialRetry(t, h, tt.policy, reg)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+			elapsed := time.Since(start)
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("Check() code = %s, want %s (err %v)", code, tt.wantCode, err)
+			}
+			if calls := h.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("server saw %d attempts, want %d", calls, tt.wantCalls)
+			}
+			if elapsed < tt.minElapsed || elapsed > tt.maxElapsed {
+				t.Errorf("Check() took %s, want between %s and %s", elapsed, tt.minElapsed, tt.maxElapsed)
+			}
+			if tt.wantCounts != nil {
+				got := attemptCounts(t, reg)
+				for key, want := range tt.wantCounts {
+					if got[key] != want {
+						t.Errorf("grpc_client_attempts_total{%s} = %v, want %v (all: %v)", key, got[key], want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUnaryClientRetryInterceptorHedging(t *testing.T) {
+	// blockFirst makes the first call hang until it is abandoned.
+	blockFirst := func(ctx context.Context, call int32) error {
+		if call == 1 {
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		handle     func(context.Context, int32) error
+		wantCode   codes.Code
+		wantCalls  int32
+		minElapsed time.Duration
+		maxElapsed time.Duration
+		wantCounts map[string]float64
+	}{
+		{
+			name:       "hedges a slow attempt after HedgingDelay",
+			policy:     RetryPolicy{MaxAttempts: 3, HedgingDelay: 50 * time.Millisecond},
+			handle:     blockFirst,
+			wantCode:   codes.OK,
+			wantCalls:  2,
+			minElapsed: 50 * time.Millisecond,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"hedge/OK": 1},
+		},
+		{
+			name:       "hedges immediately after a retryable failure",
+			policy:     RetryPolicy{MaxAttempts: 3, HedgingDelay: 5 * time.Second},
+			handle:     failFirst(1, codes.Unavailable),
+			wantCode:   codes.OK,
+			wantCalls:  2,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/Unavailable": 1, "hedge/OK": 1},
+		},
+		{
+			name:       "stops at MaxAttempts",
+			policy:     RetryPolicy{MaxAttempts: 3, HedgingDelay: 5 * time.Second},
+			handle:     failFirst(10, codes.Unavailable),
+			wantCode:   codes.Unavailable,
+			wantCalls:  3,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/Unavailable": 1, "hedge/Unavailable": 2},
+		},
+		{
+			name:       "a non-retryable failure ends the call",
+			policy:     RetryPolicy{MaxAttempts: 3, HedgingDelay: 5 * time.Second},
+			handle:     failFirst(10, codes.PermissionDenied),
+			wantCode:   codes.PermissionDenied,
+			wantCalls:  1,
+			maxElapsed: time.Second,
+			wantCounts: map[string]float64{"first/PermissionDenied": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			h := &scriptedHealth{handle: tt.handle}
+			client := dialRetry(t, h, tt.policy, reg)
+
+			start := time.Now()
+			_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			elapsed := time.Since(start)
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("Check() code = %s, want %s (err %v)", code, tt.wantCode, err)
+			}
+			if calls := h.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("server saw %d attempts, want %d", calls, tt.wantCalls)
+			}
+			if elapsed < tt.minElapsed || elapsed > tt.maxElapsed {
+				t.Errorf("Check() took %s, want between %s and %s", elapsed, tt.minElapsed, tt.maxElapsed)
+			}
+			got := attemptCounts(t, reg)
+			for key, want := range tt.wantCounts {
+				if got[key] != want {
+					t.Errorf("grpc_client_attempts_total{%s} = %v, want %v (all: %v)", key, got[key], want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	if got := (RetryPolicy{}).withDefaults().Jitter; got != 0 {
+		t.Errorf("zero Jitter = %v after defaults, want 0 (no jitter)", got)
+	}
+	if got := (RetryPolicy{Jitter: -1}).withDefaults().Jitter; got != DefaultRetryPolicy().Jitter {
+		t.Errorf("negative Jitter = %v after defaults, want %v", got, DefaultRetryPolicy().Jitter)
+	}
+
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond}.withDefaults()
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 6: 2 * time.Second} {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with 0.5 jitter = %s, want within 50ms..150ms", got)
+		}
+	}
+}
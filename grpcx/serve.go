@@ -0,0 +1,168 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// DefaultDrainTimeout bounds GracefulStop in Serve unless ServeOptions.DrainTimeout is set.
+const DefaultDrainTimeout = 15 * time.Second
+
+// ServeOptions configures Serve.
+type ServeOptions struct {
+	// DrainTimeout bounds waiting for in-flight RPCs on shutdown; RPCs still
+	// running afterwards are cancelled (default: DefaultDrainTimeout).
+	DrainTimeout time.Duration
+
+	// ShutdownDelay keeps serving after the health status flips to
+	// NOT_SERVING, giving load balancers time to stop routing new RPCs (default: 0).
+	ShutdownDelay time.Duration
+
+	// Health is flipped to NOT_SERVING when shutdown starts. Default: the
+	// health server registered on srv by NewServer or RegisterHealth, if any
+	// (see HealthServer).
+	Health *health.Server
+
+	// ReusePort sets SO_REUSEPORT on TCP listeners, so several processes can
+	// bind the same port (e.g. for zero-downtime restarts). Linux and BSDs only.
+	ReusePort bool
+}
+
+// Serve listens on addr and serves srv until ctx is cancelled, then shuts down:
+// the health server is flipped to NOT_SERVING, new RPCs keep being accepted
+// for ShutdownDelay, and in-flight RPCs are drained for up to DrainTimeout
+// before the remaining ones are cancelled. It returns nil after a graceful shutdown.
+//
+// addr is a TCP address (":9090") or a unix socket ("unix:///run/svc.sock" or
+// "unix:/run/svc.sock"); a stale socket file left by a previous run is removed,
+// while one a live process is serving fails with syscall.EADDRINUSE.
+func Serve(ctx context.Context, srv *grpc.Server, addr string, opts ServeOptions) error {
+	lis, err := Listen(ctx, addr, opts.ReusePort)
+	if err != nil {
+		return err
+	}
+	return ServeListener(ctx, srv, lis, opts)
+}
+
+// ServeListener is like Serve but accepts connections on lis.
+func ServeListener(ctx context.Context, srv *grpc.Server, lis net.Listener, opts ServeOptions) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	hs := opts.Health
+	if hs == nil {
+		hs = HealthServer(srv)
+	}
+	if hs != nil {
+		// Shutdown sets every service to NOT_SERVING and ignores later updates,
+		// e.g. from health.Health.DriveGRPC.
+		hs.Shutdown()
+	}
+	if opts.ShutdownDelay > 0 {
+		time.Sleep(opts.ShutdownDelay)
+	}
+
+	drainTimeout := opts.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = DefaultDrainTimeout
+	}
+	drained := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+		srv.Stop()
+		<-drained
+		return fmt.Errorf("draining grpc server: %w", context.DeadlineExceeded)
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Listen listens on a TCP address or a "unix:" socket address as accepted by
+// Serve, optionally with SO_REUSEPORT.
+func Listen(ctx context.Context, addr string, reusePort bool) (net.Listener, error) {
+	var lc net.ListenConfig
+
+	if path, ok := unixSocketPath(addr); ok {
+		if err := removeStaleSocket(ctx, path); err != nil {
+			return nil, fmt.Errorf("listening on %s: %w", addr, err)
+		}
+		lis, err := lc.Listen(ctx, "unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("listening on %s: %w", addr, err)
+		}
+		return lis, nil
+	}
+
+	if reusePort {
+		lc.Control = reusePortControl
+	}
+	lis, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", addr, err)
+	}
+	return lis, nil
+}
+
+// removeStaleSocket removes the socket file at path left behind by a process
+// that exited without cleaning up. A socket still accepting connections is
+// reported as syscall.EADDRINUSE; the file is only removed when dialing it is
+// refused.
+func removeStaleSocket(ctx context.Context, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	d := net.Dialer{Timeout: time.Second}
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err == nil {
+		conn.Close()
+		return syscall.EADDRINUSE
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("probing socket %s: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing stale socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// unixSocketPath extracts the path of a "unix:///path" or "unix:path" address.
+func unixSocketPath(addr string) (string, bool) {
+	rest, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return "", false
+	}
+	if p, ok := strings.CutPrefix(rest, "//"); ok {
+		return p, true
+	}
+	return rest, true
+}
@@ -3,9 +3,16 @@ package grpcx
 import (
 	"fmt"
 	"reflect"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/nikolapavicevic-001/CommonGo/config"
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
 )
 
 // Options configures the gRPC server defaults provided by CommonGo.
@@ -13,14 +20,159 @@ type Options struct {
 	// Logger is used by logging interceptors. If unset, logging interceptors are disabled.
 	Logger zerolog.Logger
 
-	// EnableHealth registers the standard gRPC health service.
+	// Logging configures the logging interceptors (slow RPC thresholds, ...).
+	Logging LoggingOptions
+
+	// EnableHealth registers the standard gRPC health service; HealthServer returns it.
 	EnableHealth bool
 
-	// EnableReflection enables gRPC server reflection.
+	// Health, if set, is registered as the health service instead of a new
+	// health server, and implies EnableHealth. Either way Serve flips the
+	// registered server to NOT_SERVING on shutdown (see HealthServer).
+	Health *health.Server `env:"-"`
+
+	// EnableReflection enables gRPC server reflection. DefaultOptions enables it only in development.
 	EnableReflection bool
 
 	// EnableOTel enables OpenTelemetry gRPC instrumentation (stats handler).
 	EnableOTel bool
+
+	// Auth, if set, adds authentication interceptors (see UnaryAuthInterceptor).
+	Auth *AuthOptions `env:"-"`
+
+	// Recovery configures the panic recovery interceptors, which are always installed.
+	// Recovery.Registerer defaults to MetricsRegisterer.
+	Recovery RecoveryOptions
+
+	// EnableMetrics adds Prometheus metrics interceptors (see UnaryMetricsInterceptor).
+	EnableMetrics bool
+
+	// MetricsRegisterer receives the metrics collectors (default: prometheus.DefaultRegisterer).
+	MetricsRegisterer prometheus.Registerer
+
+	// The fields below tune transport limits. The defaults noted on each field
+	// are set by DefaultOptions and OptionsFromEnv; zero values keep grpc-go's
+	// own defaults. The env tags allow loading them with OptionsFromEnv or
+	// config.Load.
+
+	// MaxRecvMsgSize is the largest message the server accepts, in bytes (default: 4 MiB)
+	MaxRecvMsgSize int `env:"MAX_RECV_MSG_SIZE" default:"4194304" validate:"min=1024" desc:"Maximum received message size in bytes"`
+
+	// MaxSendMsgSize is the largest message the server sends, in bytes (default: 4 MiB,
+	// matching the default client receive limit)
+	MaxSendMsgSize int `env:"MAX_SEND_MSG_SIZE" default:"4194304" validate:"min=1024" desc:"Maximum sent message size in bytes"`
+
+	// MaxConcurrentStreams limits concurrent RPCs per client connection (default: 1000)
+	MaxConcurrentStreams uint32 `env:"MAX_CONCURRENT_STREAMS" default:"1000" validate:"min=1" desc:"Maximum concurrent streams per connection"`
+
+	// ConnectionTimeout bounds connection setup, including the TLS handshake (default: 20s)
+	ConnectionTimeout time.Duration `env:"CONNECTION_TIMEOUT" default:"20s" validate:"min=1s" desc:"Connection setup timeout"`
+
+	// KeepaliveTime is how long a connection may be idle before the server pings the client (default: 1m)
+	KeepaliveTime time.Duration `env:"KEEPALIVE_TIME" default:"1m" validate:"min=1s" desc:"Idle time before the server pings the client"`
+
+	// KeepaliveTimeout is how long the server waits for a ping ack before closing the connection (default: 20s)
+	KeepaliveTimeout time.Duration `env:"KEEPALIVE_TIMEOUT" default:"20s" validate:"min=1s" desc:"Ping ack timeout before closing the connection"`
+
+	// MaxConnectionIdle closes connections without RPCs for this long (0: never)
+	MaxConnectionIdle time.Duration `env:"MAX_CONNECTION_IDLE" desc:"Close connections idle this long (0: never)"`
+
+	// MaxConnectionAge closes connections older than this, spreading clients
+	// across new replicas behind L4 load balancers (0: never)
+	MaxConnectionAge time.Duration `env:"MAX_CONNECTION_AGE" desc:"Close connections older than this (0: never)"`
+
+	// MaxConnectionAgeGrace lets RPCs finish after MaxConnectionAge (0: forever)
+	MaxConnectionAgeGrace time.Duration `env:"MAX_CONNECTION_AGE_GRACE" desc:"Grace period for RPCs after MAX_CONNECTION_AGE (0: forever)"`
+
+	// KeepaliveMinTime is the shortest client ping interval tolerated; clients
+	// pinging more often are disconnected with GOAWAY (default: 10s)
+	KeepaliveMinTime time.Duration `env:"KEEPALIVE_MIN_TIME" default:"10s" validate:"min=1s" desc:"Minimum client ping interval"`
+
+	// KeepalivePermitWithoutStream allows client pings on connections without
+	// active RPCs. DefaultOptions and OptionsFromEnv enable it.
+	KeepalivePermitWithoutStream bool `env:"KEEPALIVE_PERMIT_WITHOUT_STREAM" default:"true" desc:"Allow client pings without active RPCs"`
+}
+
+// EnvPrefix is the prefix of the standard gRPC server variables (GRPC_MAX_RECV_MSG_SIZE, ...).
+const EnvPrefix = "GRPC_"
+
+// DefaultOptions returns Options with health and OTel enabled, and reflection
+// enabled only in development (see config.CurrentEnvironment), since it
+// exposes the full API surface to anyone who can reach the port.
+func DefaultOptions(log zerolog.Logger) Options {
+	return Options{
+		Logger:           log,
+		EnableHealth:     true,
+		EnableReflection: config.CurrentEnvironment().IsDevelopment(),
+		EnableOTel:       true,
+
+		MaxRecvMsgSize:               4 << 20,
+		MaxSendMsgSize:               4 << 20,
+		MaxConcurrentStreams:         1000,
+		ConnectionTimeout:            20 * time.Second,
+		KeepaliveTime:                time.Minute,
+		KeepaliveTimeout:             20 * time.Second,
+		KeepaliveMinTime:             10 * time.Second,
+		KeepalivePermitWithoutStream: true,
+	}
+}
+
+// OptionsFromEnv returns DefaultOptions(log) with the transport limits loaded
+// from environment variables named prefix plus the env tag of each field:
+//
+//	MAX_RECV_MSG_SIZE                default 4194304
+//	MAX_SEND_MSG_SIZE                default 4194304
+//	MAX_CONCURRENT_STREAMS           default 1000
+//	CONNECTION_TIMEOUT               default 20s
+//	KEEPALIVE_TIME                   default 1m
+//	KEEPALIVE_TIMEOUT                default 20s
+//	MAX_CONNECTION_IDLE              default 0 (never)
+//	MAX_CONNECTION_AGE               default 0 (never)
+//	MAX_CONNECTION_AGE_GRACE         default 0 (forever)
+//	KEEPALIVE_MIN_TIME               default 10s
+//	KEEPALIVE_PERMIT_WITHOUT_STREAM  default true
+//
+// Use EnvPrefix for the standard names (GRPC_MAX_RECV_MSG_SIZE, ...). Auth is
+// left nil. Every malformed or invalid variable is reported in a single error.
+func OptionsFromEnv(prefix string, log zerolog.Logger) (Options, error) {
+	opts := DefaultOptions(log)
+	if err := config.LoadPrefixed(&opts, prefix, config.EnvSource()); err != nil {
+		return Options{}, err
+	}
+	return opts, nil
+}
+
+// transportOptions returns the grpc.ServerOptions for the transport limits
+// that are set, leaving grpc-go's defaults in place for zero fields.
+func (o Options) transportOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if o.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.MaxRecvMsgSize))
+	}
+	if o.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.MaxSendMsgSize))
+	}
+	if o.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(o.MaxConcurrentStreams))
+	}
+	if o.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(o.ConnectionTimeout))
+	}
+	// Zero keepalive fields are replaced with grpc-go's defaults by grpc itself.
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  o.KeepaliveTime,
+			Timeout:               o.KeepaliveTimeout,
+			MaxConnectionIdle:     o.MaxConnectionIdle,
+			MaxConnectionAge:      o.MaxConnectionAge,
+			MaxConnectionAgeGrace: o.MaxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             o.KeepaliveMinTime,
+			PermitWithoutStream: o.KeepalivePermitWithoutStream,
+		}),
+	)
+	return opts
 }
 
 // NewServer constructs a *grpc.Server with standard CommonGo interceptors and optional features enabled.
@@ -34,9 +186,39 @@ func NewServer(opts Options, extra ...grpc.ServerOption) (*grpc.Server, error) {
 	if reflect.ValueOf(opts.Logger).IsZero() {
 		return nil, fmt.Errorf("creating grpc server: Options.Logger must be set (use logger.New(...) or zerolog.Nop())")
 	}
+	if err := config.Validate(&opts); err != nil {
+		return nil, fmt.Errorf("creating grpc server: %w", err)
+	}
+	if opts.KeepaliveTime > 0 && opts.KeepaliveTimeout >= opts.KeepaliveTime {
+		return nil, fmt.Errorf("creating grpc server: KeepaliveTimeout (%s) must be shorter than KeepaliveTime (%s)", opts.KeepaliveTimeout, opts.KeepaliveTime)
+	}
+	serverOpts = append(serverOpts, opts.transportOptions()...)
+
+	unary := []grpc.UnaryServerInterceptor{
+		requestid.UnaryServerInterceptor(),
+		UnaryLoggingInterceptorWithOpts(opts.Logger, opts.Logging),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		requestid.StreamServerInterceptor(),
+		StreamLoggingInterceptorWithOpts(opts.Logger, opts.Logging),
+	}
+	if opts.EnableMetrics {
+		unary = append(unary, UnaryMetricsInterceptor(opts.MetricsRegisterer))
+		stream = append(stream, StreamMetricsInterceptor(opts.MetricsRegisterer))
+	}
+	if opts.Auth != nil {
+		unary = append(unary, UnaryAuthInterceptor(*opts.Auth))
+		stream = append(stream, StreamAuthInterceptor(*opts.Auth))
+	}
+	// Recovery runs innermost, so logging and metrics see recovered panics as codes.Internal.
+	if opts.Recovery.Registerer == nil {
+		opts.Recovery.Registerer = opts.MetricsRegisterer
+	}
+	unary = append(unary, UnaryRecoveryInterceptor(opts.Recovery))
+	stream = append(stream, StreamRecoveryInterceptor(opts.Recovery))
 	serverOpts = append(serverOpts,
-		grpc.ChainUnaryInterceptor(UnaryLoggingInterceptor(opts.Logger)),
-		grpc.ChainStreamInterceptor(StreamLoggingInterceptor(opts.Logger)),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
 
 	// OpenTelemetry
@@ -48,7 +230,9 @@ func NewServer(opts Options, extra ...grpc.ServerOption) (*grpc.Server, error) {
 
 	s := grpc.NewServer(serverOpts...)
 
-	if opts.EnableHealth {
+	if opts.Health != nil {
+		registerHealth(s, opts.Health)
+	} else if opts.EnableHealth {
 		RegisterHealth(s)
 	}
 	if opts.EnableReflection {
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// LiveHandler serves liveness probes. It reports up whenever the process can
+// serve HTTP and runs no dependency checks, so a database outage never gets
+// the pod restarted.
+func (h *Health) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, Report{Status: StatusUp})
+	})
+}
+
+// ReadyHandler serves readiness probes: 200 with a JSON report when every
+// check is up, 503 otherwise.
+func (h *Health) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Check(r.Context()))
+	})
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// DriveGRPC runs the checks every interval and sets serving statuses on hs
+// until ctx is cancelled. The overall service ("") reflects every check;
+// services maps additional gRPC service names to the checks they depend on:
+//
+//	go h.DriveGRPC(ctx, hs, 5*time.Second, map[string][]string{
+//		"devices.v1.DeviceService": {"postgres"},
+//		"events.v1.EventService":   {"postgres", "nats"},
+//	})
+func (h *Health) DriveGRPC(ctx context.Context, hs *grpchealth.Server, interval time.Duration, services map[string][]string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.updateGRPC(ctx, hs, services)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Health) updateGRPC(ctx context.Context, hs *grpchealth.Server, services map[string][]string) {
+	report := h.Check(ctx)
+	if ctx.Err() != nil {
+		// Shutdown owns the final statuses.
+		return
+	}
+	hs.SetServingStatus("", servingStatus(report.Status))
+
+	for svc, names := range services {
+		status := StatusUp
+		for _, name := range names {
+			res, ok := report.Checks[name]
+			if !ok || res.Status != StatusUp {
+				status = StatusDown
+				break
+			}
+		}
+		hs.SetServingStatus(svc, servingStatus(status))
+	}
+}
+
+func servingStatus(s Status) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if s == StatusUp {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
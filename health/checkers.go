@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+)
+
+// Postgres returns a Checker that pings the pool.
+func Postgres(pool *pgxpool.Pool) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return pool.Ping(ctx)
+	})
+}
+
+// NATS returns a Checker that fails unless nc is connected. A reconnecting
+// connection is reported as down so traffic is routed elsewhere until it recovers.
+func NATS(nc *nats.Conn) Checker {
+	return CheckerFunc(func(context.Context) error {
+		if status := nc.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("nats connection is %s", status)
+		}
+		return nil
+	})
+}
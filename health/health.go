@@ -0,0 +1,188 @@
+// Package health aggregates dependency checks into liveness and readiness
+// reports for HTTP probes and the gRPC health service.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single check unless overridden with WithTimeout.
+const DefaultTimeout = 2 * time.Second
+
+// Checker reports whether a dependency is usable.
+type Checker interface {
+	// Check returns nil if the dependency is healthy.
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check implements Checker.
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+// Status is the outcome of a check or of a whole report.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report aggregates check results. Status is up only if every check is up.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// CheckOption configures a registered check.
+type CheckOption func(*check)
+
+// WithTimeout bounds the check (default: DefaultTimeout).
+func WithTimeout(d time.Duration) CheckOption {
+	return func(c *check) { c.timeout = d }
+}
+
+// WithCacheTTL reuses the last result for d, so frequent probes from several
+// sources do not hammer the dependency (default: no caching).
+func WithCacheTTL(d time.Duration) CheckOption {
+	return func(c *check) { c.ttl = d }
+}
+
+type check struct {
+	name    string
+	checker Checker
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu   sync.Mutex
+	last Result
+}
+
+// Health holds the registered readiness checks.
+type Health struct {
+	mu     sync.RWMutex
+	checks []*check
+}
+
+// New returns an empty Health.
+func New() *Health {
+	return &Health{}
+}
+
+// Register adds a readiness check under name, replacing any check with the same name.
+func (h *Health) Register(name string, c Checker, opts ...CheckOption) {
+	ch := &check{name: name, checker: c, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(ch)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, existing := range h.checks {
+		if existing.name == name {
+			h.checks[i] = ch
+			return
+		}
+	}
+	h.checks = append(h.checks, ch)
+}
+
+// Check runs every registered check concurrently and aggregates the results.
+func (h *Health) Check(ctx context.Context) Report {
+	return h.CheckOnly(ctx)
+}
+
+// CheckOnly is like Check but runs only the named checks (all if none are given).
+// Unknown names are reported as down.
+func (h *Health) CheckOnly(ctx context.Context, names ...string) Report {
+	h.mu.RLock()
+	checks := make([]*check, 0, len(h.checks))
+	if len(names) == 0 {
+		checks = append(checks, h.checks...)
+	} else {
+		for _, name := range names {
+			checks = append(checks, h.find(name))
+		}
+	}
+	h.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// find returns the check named name, or a failing placeholder. h.mu must be held.
+func (h *Health) find(name string) *check {
+	for _, c := range h.checks {
+		if c.name == name {
+			return c
+		}
+	}
+	return &check{name: name, checker: CheckerFunc(func(context.Context) error {
+		return fmt.Errorf("no check registered as %q", name)
+	}), timeout: DefaultTimeout}
+}
+
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl > 0 && !c.last.CheckedAt.IsZero() && time.Since(c.last.CheckedAt) < c.ttl {
+		return c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// Checkers that ignore ctx must not hold up the report past the timeout.
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.checker.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := Result{Status: StatusUp, Duration: time.Since(start), CheckedAt: start}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", c.timeout)
+		}
+		res.Status, res.Error = StatusDown, err.Error()
+	}
+	c.last = res
+	return res
+}
@@ -0,0 +1,177 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultMaxBodyLogBytes is the body capture limit used when
+// RequestLoggerOptions.MaxBodyBytes is zero.
+const DefaultMaxBodyLogBytes = 4096
+
+// DefaultRedactedBodyFields are the JSON and form field names redacted when
+// RequestLoggerOptions.RedactBodyFields is nil.
+var DefaultRedactedBodyFields = []string{
+	"password", "token", "authorization", "secret", "api_key", "apikey",
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the rest.
+// Writes never fail, so it is safe to use as a tee.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// teeReadCloser copies everything the handler reads from the request body
+// into a cappedBuffer, so capture never reads ahead of the handler.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyKind classifies a Content-Type for logging; only JSON and forms are captured.
+type bodyKind int
+
+const (
+	bodyOther bodyKind = iota
+	bodyJSON
+	bodyForm
+)
+
+func bodyKindOf(contentType string) bodyKind {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return bodyOther
+	}
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return bodyJSON
+	case mt == "application/x-www-form-urlencoded":
+		return bodyForm
+	default:
+		return bodyOther
+	}
+}
+
+// bodyRedactor redacts fields whose name contains one of its keys (case-insensitive).
+type bodyRedactor struct {
+	keys []string
+	re   *regexp.Regexp
+}
+
+func newBodyRedactor(fields []string) *bodyRedactor {
+	r := &bodyRedactor{}
+	var alts []string
+	for _, f := range fields {
+		f = strings.ToLower(f)
+		r.keys = append(r.keys, f)
+		alts = append(alts, regexp.QuoteMeta(f))
+	}
+	if len(alts) > 0 {
+		// Fallback for truncated JSON that cannot be parsed: redact string values of matching keys.
+		r.re = regexp.MustCompile(`(?i)("[^"]*(?:` + strings.Join(alts, "|") + `)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+	}
+	return r
+}
+
+func (r *bodyRedactor) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *bodyRedactor) redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if r.sensitive(k) {
+				t[k] = "[REDACTED]"
+			} else {
+				t[k] = r.redactValue(child)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = r.redactValue(child)
+		}
+	}
+	return v
+}
+
+// decodeJSON decodes a single JSON document, keeping numbers as json.Number
+// so 64-bit IDs are logged exactly rather than rounded through float64.
+func decodeJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("trailing data after JSON document")
+	}
+	return doc, nil
+}
+
+// addBody adds the captured body to ev under key, redacted according to kind.
+// Complete JSON bodies are logged as embedded JSON, anything else as a string.
+func (r *bodyRedactor) addBody(ev *zerolog.Event, key string, kind bodyKind, body *cappedBuffer) *zerolog.Event {
+	if body.buf.Len() == 0 {
+		return ev
+	}
+	if body.truncated {
+		ev = ev.Bool(key+"_truncated", true)
+	}
+
+	raw := body.buf.Bytes()
+	switch kind {
+	case bodyJSON:
+		if !body.truncated {
+			if doc, err := decodeJSON(raw); err == nil {
+				if out, err := json.Marshal(r.redactValue(doc)); err == nil {
+					return ev.RawJSON(key, out)
+				}
+			}
+		}
+		if r.re == nil {
+			return ev.Bytes(key, raw)
+		}
+		return ev.Str(key, r.re.ReplaceAllString(string(raw), `$1"[REDACTED]"`))
+	case bodyForm:
+		values, _ := url.ParseQuery(string(raw))
+		redacted := make(map[string]bool)
+		for k := range values {
+			if r.sensitive(k) {
+				redacted[strings.ToLower(k)] = true
+			}
+		}
+		return ev.Str(key, redactQuery(values, redacted))
+	default:
+		return ev
+	}
+}
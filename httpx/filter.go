@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// pathMatcher matches request paths against RequestLoggerOptions.SkipPaths entries:
+//
+//	/health          exact match
+//	/static/*        prefix match: everything under /static/ (as in chi routes)
+//	/api/*/status    glob match with path.Match syntax (* stays within a segment)
+//	~^/debug/pprof/  regular expression (leading ~)
+type pathMatcher struct {
+	exact    map[string]bool
+	prefixes []string
+	globs    []string
+	regexes  []*regexp.Regexp
+}
+
+// newPathMatcher compiles patterns. It panics on invalid globs or regular
+// expressions, since they are programmer errors caught at startup.
+func newPathMatcher(patterns []string) *pathMatcher {
+	m := &pathMatcher{exact: make(map[string]bool)}
+	for _, p := range patterns {
+		switch {
+		case strings.HasPrefix(p, "~"):
+			m.regexes = append(m.regexes, regexp.MustCompile(p[1:]))
+		case strings.HasSuffix(p, "/*") && !strings.ContainsAny(p[:len(p)-1], "*?["):
+			m.prefixes = append(m.prefixes, p[:len(p)-1])
+		case strings.ContainsAny(p, "*?["):
+			if _, err := path.Match(p, ""); err != nil {
+				panic(fmt.Sprintf("httpx: invalid skip path pattern %q: %v", p, err))
+			}
+			m.globs = append(m.globs, p)
+		default:
+			m.exact[p] = true
+		}
+	}
+	return m
+}
+
+func (m *pathMatcher) match(p string) bool {
+	if m.exact[p] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	for _, g := range m.globs {
+		if ok, _ := path.Match(g, p); ok {
+			return true
+		}
+	}
+	for _, re := range m.regexes {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled reports whether an event should be kept at the given rate.
+func sampled(rate float64) bool {
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
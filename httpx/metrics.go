@@ -0,0 +1,155 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/nikolapavicevic-001/CommonGo/internal/promutil"
+)
+
+// MetricsOptions configures the metrics middleware.
+type MetricsOptions struct {
+	// Namespace prefixes every metric name (e.g. "devices" gives devices_http_requests_total).
+	Namespace string
+
+	// Registerer receives the collectors (default: prometheus.DefaultRegisterer).
+	Registerer prometheus.Registerer
+
+	// DurationBuckets are the latency histogram buckets in seconds (default: prometheus.DefBuckets).
+	DurationBuckets []float64
+
+	// SizeBuckets are the response size histogram buckets in bytes (default: 100B to 10MB, exponential).
+	SizeBuckets []float64
+}
+
+// unmatchedRoute labels requests that matched no chi route, keeping 404 scans
+// from creating one series per probed path.
+const unmatchedRoute = "unmatched"
+
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// WithMetrics adds Prometheus request metrics to the router. See Metrics.
+func WithMetrics(opts MetricsOptions) RouterOption {
+	return func(r *chi.Mux) {
+		r.Use(Metrics(opts))
+	}
+}
+
+// Metrics returns a middleware recording, by method, chi route pattern
+// (e.g. /users/{id}, never the raw path) and status class (2xx, 4xx, ...):
+//
+//	http_requests_total                 counter
+//	http_request_duration_seconds       histogram
+//	http_response_size_bytes            histogram
+//	http_requests_in_flight             gauge (by method only)
+//
+// It must be installed on the top-level router so the route pattern is
+// complete once the request has been served. Collectors already registered
+// by another router are reused.
+func Metrics(opts MetricsOptions) func(http.Handler) http.Handler {
+	m := newHTTPMetrics(opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight := m.inFlight.WithLabelValues(r.Method)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			labels := prometheus.Labels{
+				"method":       r.Method,
+				"route":        routePattern(r),
+				"status_class": statusClass(ww.Status()),
+			}
+			m.requests.With(labels).Inc()
+			m.duration.With(labels).Observe(time.Since(start).Seconds())
+			m.size.With(labels).Observe(float64(ww.BytesWritten()))
+		})
+	}
+}
+
+// MetricsHandler serves the metrics registered with prometheus.DefaultRegisterer.
+// Mount it with r.Handle("/metrics", httpx.MetricsHandler()).
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// MetricsHandlerFor serves the metrics gathered by g, e.g. the
+// *prometheus.Registry passed as MetricsOptions.Registerer.
+func MetricsHandlerFor(g prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+}
+
+func newHTTPMetrics(opts MetricsOptions) *httpMetrics {
+	reg := opts.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	durationBuckets := opts.DurationBuckets
+	if durationBuckets == nil {
+		durationBuckets = prometheus.DefBuckets
+	}
+	sizeBuckets := opts.SizeBuckets
+	if sizeBuckets == nil {
+		sizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+	}
+
+	labels := []string{"method", "route", "status_class"}
+	return &httpMetrics{
+		requests: promutil.Register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests handled.",
+		}, labels)),
+		duration: promutil.Register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   durationBuckets,
+		}, labels)),
+		size: promutil.Register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "http_response_size_bytes",
+			Help:      "HTTP response body size in bytes.",
+			Buckets:   sizeBuckets,
+		}, labels)),
+		inFlight: promutil.Register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests currently being served.",
+		}, []string{"method"})),
+	}
+}
+
+// routePattern returns the matched chi route pattern, or unmatchedRoute.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if p := rctx.RoutePattern(); p != "" {
+			return p
+		}
+	}
+	return unmatchedRoute
+}
+
+// statusClass maps a status code to "1xx".."5xx". Handlers that never call
+// WriteHeader report 0, which net/http sends as 200.
+func statusClass(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -1,9 +1,14 @@
 package httpx
 
 import (
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
 
@@ -11,112 +16,203 @@ import (
 )
 
 // RequestLogger returns a middleware that logs HTTP requests using zerolog.
-// It logs method, path, status, duration, and correlates with request_id.
+// It logs method, path, route pattern, status, duration, and correlates with request_id.
+// It is RequestLoggerWithOpts with default options.
 func RequestLogger(log zerolog.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
+	return RequestLoggerWithOpts(log, RequestLoggerOptions{})
+}
 
-			// Get or generate request ID
-			requestID := middleware.GetReqID(r.Context())
-			if requestID == "" {
-				requestID = "unknown"
-			}
+// RequestLoggerWithOptions returns a request logger with customizable options.
+type RequestLoggerOptions struct {
+	// SkipPaths are paths that should not be logged (e.g., /health, /metrics).
+	// Entries match exactly unless they end in "/*" (prefix, e.g. /static/*),
+	// contain other glob characters (path.Match, e.g. /api/*/status) or start
+	// with "~" (regular expression, e.g. ~^/debug/pprof/).
+	SkipPaths []string
 
-			// Create a logger with request context
-			reqLog := log.With().
-				Str("request_id", requestID).
-				Str("method", r.Method).
-				Str("path", r.URL.Path).
-				Str("remote_addr", r.RemoteAddr).
-				Logger()
+	// AlwaysLogStatus, if set, logs requests whose status is at least this value
+	// (e.g. 400) even when they are skipped or not sampled.
+	AlwaysLogStatus int
 
-			// Attach logger to context
-			ctx := logger.With(r.Context(), reqLog)
-			r = r.WithContext(ctx)
+	// SlowThreshold marks requests taking longer as slow: they are logged at
+	// least at Warn with slow=true and are never dropped by sampling. Zero disables.
+	SlowThreshold time.Duration
 
-			// Wrap response writer to capture status code
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	// SlowThresholds overrides SlowThreshold per chi route pattern (e.g. /reports/{id}).
+	SlowThresholds map[string]time.Duration
 
-			// Process request
-			next.ServeHTTP(ww, r)
+	// OnSlow, if set, is called for every slow request, e.g. to emit a metric or trace event.
+	OnSlow func(r *http.Request, route string, duration time.Duration)
 
-			// Log completion
-			duration := time.Since(start)
-			status := ww.Status()
+	// SampleRates maps chi route patterns (e.g. /api/items/{id}) to the fraction
+	// of successful (< 400) requests to log, between 0 and 1. Sampled entries
+	// carry a sample_rate field so counts can be re-weighted.
+	SampleRates map[string]float64
 
-			event := reqLog.Info()
-			if status >= 500 {
-				event = reqLog.Error()
-			} else if status >= 400 {
-				event = reqLog.Warn()
-			}
+	// LogRequestBody logs the request body (use with caution for large payloads).
+	// Only JSON and form bodies are logged, and only the part the handler reads.
+	LogRequestBody bool
 
-			event.
-				Int("status", status).
-				Int("bytes", ww.BytesWritten()).
-				Dur("duration", duration).
-				Msg("request completed")
-		})
-	}
-}
+	// LogResponseBody logs the response body (use with caution).
+	// Only JSON and form bodies are logged.
+	LogResponseBody bool
 
-// RequestLoggerWithOptions returns a request logger with customizable options.
-type RequestLoggerOptions struct {
-	// SkipPaths are paths that should not be logged (e.g., /health, /metrics)
-	SkipPaths []string
+	// MaxBodyBytes caps how much of each body is captured (default: DefaultMaxBodyLogBytes).
+	// Longer bodies are logged truncated with a <field>_truncated flag.
+	MaxBodyBytes int
 
-	// LogRequestBody logs the request body (use with caution for large payloads)
-	LogRequestBody bool
+	// RedactBodyFields lists JSON/form field names whose values are logged as
+	// [REDACTED]; a field matches if its name contains an entry (case-insensitive).
+	// Nil uses DefaultRedactedBodyFields.
+	RedactBodyFields []string
 
-	// LogResponseBody logs the response body (use with caution)
-	LogResponseBody bool
+	// RedactQueryParams lists query parameters whose values are logged as
+	// [REDACTED] (case-insensitive). Nil uses DefaultRedactedQueryParams.
+	RedactQueryParams []string
+}
+
+// DefaultRedactedQueryParams are the query parameters redacted when
+// RequestLoggerOptions.RedactQueryParams is nil.
+var DefaultRedactedQueryParams = []string{
+	"access_token", "api_key", "apikey", "code", "key", "password", "secret", "signature", "token",
 }
 
 // RequestLoggerWithOpts returns a middleware with custom options.
 func RequestLoggerWithOpts(log zerolog.Logger, opts RequestLoggerOptions) func(http.Handler) http.Handler {
-	skipMap := make(map[string]bool)
-	for _, p := range opts.SkipPaths {
-		skipMap[p] = true
+	skip := newPathMatcher(opts.SkipPaths)
+
+	redact := opts.RedactQueryParams
+	if redact == nil {
+		redact = DefaultRedactedQueryParams
+	}
+	redactSet := make(map[string]bool, len(redact))
+	for _, p := range redact {
+		redactSet[strings.ToLower(p)] = true
 	}
 
+	maxBody := opts.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBodyLogBytes
+	}
+	bodyFields := opts.RedactBodyFields
+	if bodyFields == nil {
+		bodyFields = DefaultRedactedBodyFields
+	}
+	bodies := newBodyRedactor(bodyFields)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip logging for specified paths
-			if skipMap[r.URL.Path] {
+			// Skip logging for specified paths, unless their status may still need logging
+			skipped := skip.match(r.URL.Path)
+			if skipped && opts.AlwaysLogStatus <= 0 {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			start := time.Now()
-			requestID := middleware.GetReqID(r.Context())
-			if requestID == "" {
-				requestID = "unknown"
+			rid := requestID(r)
+			if rid == "" {
+				rid = "unknown"
 			}
 
-			reqLog := log.With().
-				Str("request_id", requestID).
+			reqLogCtx := log.With().
+				Str("request_id", rid).
 				Str("method", r.Method).
 				Str("path", r.URL.Path).
+				Str("proto", r.Proto).
 				Str("remote_addr", r.RemoteAddr).
-				Str("user_agent", r.UserAgent()).
-				Logger()
+				Str("user_agent", r.UserAgent())
+			if r.URL.RawQuery != "" {
+				reqLogCtx = reqLogCtx.Str("query", redactQuery(r.URL.Query(), redactSet))
+			}
+			reqLog := reqLogCtx.Logger()
 
 			ctx := logger.With(r.Context(), reqLog)
 			r = r.WithContext(ctx)
 
+			var reqBody *cappedBuffer
+			reqKind := bodyKindOf(r.Header.Get("Content-Type"))
+			if opts.LogRequestBody && reqKind != bodyOther && r.Body != nil && r.Body != http.NoBody {
+				reqBody = &cappedBuffer{max: maxBody}
+				r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+			}
+
+			// Tee keeps the wrapper's Flusher/Hijacker behaviour; the copy is bounded.
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			var respBody *cappedBuffer
+			if opts.LogResponseBody {
+				respBody = &cappedBuffer{max: maxBody}
+				ww.Tee(respBody)
+			}
+
 			next.ServeHTTP(ww, r)
 
 			duration := time.Since(start)
 			status := ww.Status()
 
+			// The route pattern and URL params are only known once routing has completed.
+			rctx := chi.RouteContext(r.Context())
+			var route string
+			if rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			threshold := opts.SlowThreshold
+			if t, ok := opts.SlowThresholds[route]; ok {
+				threshold = t
+			}
+			slow := threshold > 0 && duration > threshold
+			if slow && opts.OnSlow != nil {
+				opts.OnSlow(r, route, duration)
+			}
+
+			forced := opts.AlwaysLogStatus > 0 && status >= opts.AlwaysLogStatus
+			if skipped && !forced {
+				return
+			}
+			rate, sampledRoute := opts.SampleRates[route]
+			sampledRoute = sampledRoute && status < 400 && !forced && !slow
+			if sampledRoute && !sampled(rate) {
+				return
+			}
+
 			event := reqLog.Info()
 			if status >= 500 {
 				event = reqLog.Error()
-			} else if status >= 400 {
+			} else if status >= 400 || slow {
 				event = reqLog.Warn()
 			}
+			if slow {
+				event = event.Bool("slow", true).Dur("slow_threshold", threshold)
+			}
+			if sampledRoute {
+				event = event.Float64("sample_rate", rate)
+			}
+
+			if rctx != nil {
+				if route != "" {
+					event = event.Str("route", route)
+				}
+				if len(rctx.URLParams.Keys) > 0 {
+					params := zerolog.Dict()
+					for i, k := range rctx.URLParams.Keys {
+						if k != "*" || rctx.URLParams.Values[i] != "" {
+							params = params.Str(k, rctx.URLParams.Values[i])
+						}
+					}
+					event = event.Dict("params", params)
+				}
+			}
+			ct := ww.Header().Get("Content-Type")
+			if ct != "" {
+				event = event.Str("content_type", ct)
+			}
+			if reqBody != nil {
+				event = bodies.addBody(event, "request_body", reqKind, reqBody)
+			}
+			if respBody != nil {
+				event = bodies.addBody(event, "response_body", bodyKindOf(ct), respBody)
+			}
 
 			event.
 				Int("status", status).
@@ -127,3 +223,28 @@ func RequestLoggerWithOpts(log zerolog.Logger, opts RequestLoggerOptions) func(h
 	}
 }
 
+// redactQuery encodes q, sorted by key, with the values of redacted parameters replaced.
+func redactQuery(q url.Values, redacted map[string]bool) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range q[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			if redacted[strings.ToLower(k)] {
+				b.WriteString("[REDACTED]")
+			} else {
+				b.WriteString(url.QueryEscape(v))
+			}
+		}
+	}
+	return b.String()
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
 )
 
 // ErrorResponse is the standard error envelope.
@@ -41,11 +43,11 @@ func WriteJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}
 
 // WriteData writes a success response with data wrapped in the standard envelope.
 func WriteData(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
-	requestID := middleware.GetReqID(r.Context())
+	rid := requestID(r)
 
 	resp := Response{
 		Data:      data,
-		RequestID: requestID,
+		RequestID: rid,
 	}
 
 	WriteJSON(w, r, status, resp)
@@ -53,12 +55,12 @@ func WriteData(w http.ResponseWriter, r *http.Request, status int, data interfac
 
 // WriteDataWithMeta writes a success response with data and metadata.
 func WriteDataWithMeta(w http.ResponseWriter, r *http.Request, status int, data interface{}, meta interface{}) {
-	requestID := middleware.GetReqID(r.Context())
+	rid := requestID(r)
 
 	resp := Response{
 		Data:      data,
 		Meta:      meta,
-		RequestID: requestID,
+		RequestID: rid,
 	}
 
 	WriteJSON(w, r, status, resp)
@@ -66,14 +68,14 @@ func WriteDataWithMeta(w http.ResponseWriter, r *http.Request, status int, data
 
 // WriteError writes an error response with the standard envelope.
 func WriteError(w http.ResponseWriter, r *http.Request, status int, code string, message string) {
-	requestID := middleware.GetReqID(r.Context())
+	rid := requestID(r)
 
 	resp := ErrorResponse{
 		Error: ErrorDetail{
 			Code:    code,
 			Message: message,
 		},
-		RequestID: requestID,
+		RequestID: rid,
 	}
 
 	WriteJSON(w, r, status, resp)
@@ -126,3 +128,11 @@ func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// requestID returns the request ID set by requestid.Middleware, falling back
+// to chi's middleware.RequestID for routers not built with NewRouter.
+func requestID(r *http.Request) string {
+	if id := requestid.FromContext(r.Context()); id != "" {
+		return id
+	}
+	return middleware.GetReqID(r.Context())
+}
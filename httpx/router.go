@@ -3,24 +3,29 @@ package httpx
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+
+	"github.com/nikolapavicevic-001/CommonGo/config"
+	"github.com/nikolapavicevic-001/CommonGo/health"
+	"github.com/nikolapavicevic-001/CommonGo/requestid"
 )
 
 // RouterOption is a function that configures a chi.Mux router.
 type RouterOption func(*chi.Mux)
 
 // NewRouter creates a new chi.Mux with standard middlewares applied.
-// Default middlewares: RequestID, RealIP, Recoverer.
+// Default middlewares: requestid.Middleware, RealIP, Recoverer.
 // Use options to customize behavior (CORS, timeouts, etc.).
 func NewRouter(opts ...RouterOption) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Default middlewares
-	r.Use(middleware.RequestID)
+	r.Use(requestid.Middleware)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 
@@ -52,11 +57,32 @@ func WithCORS(allowedOrigins []string, allowedMethods []string, allowedHeaders [
 	}
 }
 
-// WithCORSDefaults adds CORS middleware with permissive defaults.
-// Allows all origins, common methods, and standard headers.
+// WithCORSDefaults adds CORS middleware with common methods and standard headers.
+// Origins are read from the comma-separated CORS_ALLOWED_ORIGINS variable and
+// default to all origins ("*"). In production (see config.CurrentEnvironment)
+// wildcard origins are refused: only explicitly listed origins are allowed,
+// and without any, cross-origin requests are rejected.
 func WithCORSDefaults() RouterOption {
+	production := config.CurrentEnvironment().IsProduction()
+
+	var origins []string
+	for _, o := range strings.Split(config.GetEnv("CORS_ALLOWED_ORIGINS", "*"), ",") {
+		o = strings.TrimSpace(o)
+		if o == "" || (production && strings.Contains(o, "*")) {
+			continue
+		}
+		origins = append(origins, o)
+	}
+	if len(origins) == 0 {
+		// The cors package treats an empty origin list as "*", so deny explicitly.
+		return func(r *chi.Mux) {
+			r.Use(cors.Handler(cors.Options{
+				AllowOriginFunc: func(*http.Request, string) bool { return false },
+			}))
+		}
+	}
 	return WithCORS(
-		[]string{"*"},
+		origins,
 		[]string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		[]string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
 	)
@@ -78,6 +104,30 @@ func WithHeartbeat(path string) RouterOption {
 	}
 }
 
+// WithHealth serves h's liveness report at /livez and readiness report
+// (JSON detail, 503 when a check fails) at /readyz. Like WithHeartbeat it is
+// implemented as middleware, so the endpoints bypass routing and auth.
+func WithHealth(h *health.Health) RouterOption {
+	live, ready := h.LiveHandler(), h.ReadyHandler()
+	return func(r *chi.Mux) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodGet || req.Method == http.MethodHead {
+					switch req.URL.Path {
+					case "/livez":
+						live.ServeHTTP(w, req)
+						return
+					case "/readyz":
+						ready.ServeHTTP(w, req)
+						return
+					}
+				}
+				next.ServeHTTP(w, req)
+			})
+		})
+	}
+}
+
 // WithStripSlashes adds middleware to strip trailing slashes from URLs.
 func WithStripSlashes() RouterOption {
 	return func(r *chi.Mux) {
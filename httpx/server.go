@@ -0,0 +1,248 @@
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ServerConfig configures an HTTP server built by NewServer.
+// Zero values fall back to the defaults noted on each field; the env tags allow
+// loading it with config.Load (e.g. nested under prefix:"HTTP_").
+type ServerConfig struct {
+	// Addr is the listen address (default: ":8080")
+	Addr string `env:"ADDR" default:":8080" validate:"hostport" desc:"HTTP listen address"`
+
+	// ReadTimeout bounds reading the entire request, including the body (default: 15s)
+	ReadTimeout time.Duration `env:"READ_TIMEOUT" default:"15s" desc:"Maximum time to read a request"`
+
+	// ReadHeaderTimeout bounds reading request headers, guarding against slowloris (default: 5s)
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" default:"5s" desc:"Maximum time to read request headers"`
+
+	// WriteTimeout bounds writing the response (default: 30s)
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" default:"30s" desc:"Maximum time to write a response"`
+
+	// IdleTimeout is how long keep-alive connections stay open between requests (default: 120s)
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" default:"120s" desc:"Keep-alive idle timeout"`
+
+	// MaxHeaderBytes limits the size of request headers (default: 1 MiB)
+	MaxHeaderBytes int `env:"MAX_HEADER_BYTES" default:"1048576" validate:"min=1" desc:"Maximum request header size in bytes"`
+
+	// ShutdownTimeout bounds draining in-flight requests in Serve (default: 15s)
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"15s" desc:"Maximum time to drain in-flight requests on shutdown"`
+
+	// TLSCertFile and TLSKeyFile enable TLS when both are set. The files are
+	// re-read when they change, so renewed certificates are picked up without a restart.
+	TLSCertFile string `env:"TLS_CERT_FILE" desc:"PEM certificate file; enables TLS together with TLS_KEY_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE" desc:"PEM private key file"`
+
+	// TLSReloadInterval is how often the certificate files are checked for changes (default: 1m)
+	TLSReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL" default:"1m" desc:"Interval between certificate file change checks"`
+}
+
+// DefaultServerConfig returns a ServerConfig with production-safe defaults.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Addr:              ":8080",
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		ShutdownTimeout:   15 * time.Second,
+		TLSReloadInterval: time.Minute,
+	}
+}
+
+// Server wraps an *http.Server with graceful, context-driven shutdown.
+type Server struct {
+	srv             *http.Server
+	shutdownTimeout time.Duration
+	tls             bool
+}
+
+// NewServer builds a Server for handler. Unset fields of cfg use the
+// DefaultServerConfig values. If TLS files are configured, the certificate is
+// loaded immediately so misconfiguration fails at startup.
+func NewServer(cfg ServerConfig, handler http.Handler) (*Server, error) {
+	def := DefaultServerConfig()
+	if cfg.Addr == "" {
+		cfg.Addr = def.Addr
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = def.ReadTimeout
+	}
+	if cfg.ReadHeaderTimeout <= 0 {
+		cfg.ReadHeaderTimeout = def.ReadHeaderTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = def.WriteTimeout
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
+	if cfg.MaxHeaderBytes <= 0 {
+		cfg.MaxHeaderBytes = def.MaxHeaderBytes
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = def.ShutdownTimeout
+	}
+	if cfg.TLSReloadInterval <= 0 {
+		cfg.TLSReloadInterval = def.TLSReloadInterval
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	s := &Server{srv: srv, shutdownTimeout: cfg.ShutdownTimeout}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("creating http server: TLSCertFile and TLSKeyFile must be set together")
+	}
+	if cfg.TLSCertFile != "" {
+		certs, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("creating http server: %w", err)
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		s.tls = true
+	}
+
+	return s, nil
+}
+
+// HTTPServer returns the underlying *http.Server, e.g. for app.HTTPServer.
+func (s *Server) HTTPServer() *http.Server { return s.srv }
+
+// Serve listens on the configured address and serves until ctx is cancelled,
+// then stops accepting connections and drains in-flight requests for up to
+// ShutdownTimeout before closing the remaining connections. It returns nil
+// after a graceful shutdown, including one started with Shutdown.
+func (s *Server) Serve(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.srv.Addr, err)
+	}
+	return s.ServeListener(ctx, lis)
+}
+
+// ServeListener is like Serve but accepts connections on lis.
+func (s *Server) ServeListener(ctx context.Context, lis net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if s.tls {
+			errCh <- s.srv.ServeTLS(lis, "", "")
+		} else {
+			errCh <- s.srv.Serve(lis)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		// Server.Shutdown (or http.Server.Shutdown via HTTPServer) is a clean stop.
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownTimeout)
+	defer cancel()
+	if err := s.srv.Shutdown(shutdownCtx); err != nil {
+		s.srv.Close()
+		return fmt.Errorf("shutting down http server: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops a server started with Serve or via HTTPServer.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// certReloader serves a certificate pair from disk, re-reading it when the
+// files change. Changes are checked at most once per interval, on handshake.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTimes  [2]time.Time
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. If a changed
+// certificate fails to load, the previous one keeps being served.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= r.interval {
+		r.lastCheck = time.Now()
+		if mt, err := r.stat(); err == nil && mt != r.modTimes {
+			_ = r.reloadLocked()
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastCheck = time.Now()
+	return r.reloadLocked()
+}
+
+func (r *certReloader) reloadLocked() error {
+	mt, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.cert, r.modTimes = &cert, mt
+	return nil
+}
+
+func (r *certReloader) stat() ([2]time.Time, error) {
+	var mt [2]time.Time
+	for i, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return mt, fmt.Errorf("reading TLS file: %w", err)
+		}
+		mt[i] = fi.ModTime()
+	}
+	return mt, nil
+}
@@ -0,0 +1,24 @@
+// Package promutil holds Prometheus helpers shared by the httpx and grpcx metrics.
+package promutil
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers c with reg, returning the already-registered collector
+// if an identical one exists, so several routers or servers can share a
+// registry. Any other registration error panics, like prometheus.MustRegister.
+func Register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
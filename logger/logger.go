@@ -5,21 +5,30 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/nikolapavicevic-001/CommonGo/config"
 )
 
 type ctxKey struct{}
 
 // New creates a new zerolog.Logger with the specified level and service name.
-// The logger outputs to os.Stdout with pretty console formatting in development
-// or JSON formatting based on the LOG_FORMAT environment variable.
+// The logger outputs to os.Stdout as JSON when LOG_FORMAT is "json" and with
+// pretty console formatting otherwise. If LOG_FORMAT is unset, JSON is used in
+// staging and production (see config.CurrentEnvironment) and console elsewhere.
 func New(level string, serviceName string) zerolog.Logger {
 	var output io.Writer = os.Stdout
 
-	// Use console writer for human-readable output if LOG_FORMAT != "json"
-	if os.Getenv("LOG_FORMAT") != "json" {
+	format := os.Getenv("LOG_FORMAT")
+	if format == "" && config.CurrentEnvironment().IsDeployed() {
+		format = "json"
+	}
+
+	// Use console writer for human-readable output unless JSON was selected
+	if format != "json" {
 		output = zerolog.ConsoleWriter{
 			Out:        os.Stdout,
 			TimeFormat: time.RFC3339,
@@ -36,6 +45,44 @@ func New(level string, serviceName string) zerolog.Logger {
 		Logger()
 }
 
+// LevelVar is a log level that can be changed while the service is running,
+// e.g. from a config.Watcher subscription. The zero value is InfoLevel.
+type LevelVar struct {
+	level atomic.Int32
+}
+
+// NewLevelVar returns a LevelVar set to level.
+func NewLevelVar(level string) *LevelVar {
+	lv := &LevelVar{}
+	lv.Set(level)
+	return lv
+}
+
+// Set changes the level (trace/debug/info/warn/error/fatal/panic).
+func (l *LevelVar) Set(level string) {
+	l.level.Store(int32(parseLevel(level) - zerolog.InfoLevel))
+}
+
+// Level returns the current level.
+func (l *LevelVar) Level() zerolog.Level {
+	return zerolog.Level(l.level.Load()) + zerolog.InfoLevel
+}
+
+// Sample implements zerolog.Sampler, rejecting levels below the current one.
+// zerolog consults the sampler before an event is built, so filtered events
+// cost nothing and Enabled reports false for them.
+func (l *LevelVar) Sample(level zerolog.Level) bool {
+	return level >= l.Level() || level == zerolog.NoLevel
+}
+
+// NewWithLevelVar is like New but filters events through lv, so the level
+// can be changed at runtime with lv.Set. lv is installed as the logger's
+// sampler: calling Sample on the returned logger replaces it, and
+// zerolog.DisableSampling turns the filtering off.
+func NewWithLevelVar(lv *LevelVar, serviceName string) zerolog.Logger {
+	return New("trace", serviceName).Sample(lv)
+}
+
 // With attaches the logger to the context for later retrieval.
 func With(ctx context.Context, log zerolog.Logger) context.Context {
 	return context.WithValue(ctx, ctxKey{}, log)
@@ -6,32 +6,39 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
+
+	"github.com/nikolapavicevic-001/CommonGo/config"
 )
 
 // Config holds NATS connection configuration.
+//
+// The env tags name the variables read by ConfigFromEnv, relative to its prefix.
 type Config struct {
 	// URL is the NATS server URL (e.g., nats://localhost:4222)
-	URL string
+	URL string `env:"URL" required:"true" desc:"NATS server URL(s), comma-separated"`
 
 	// Name is the client connection name for identification
-	Name string
+	Name string `env:"NAME" desc:"Client connection name (defaults to SERVICE_NAME)"`
 
 	// ReconnectWait is the time to wait between reconnect attempts (default: 2s)
-	ReconnectWait time.Duration
+	ReconnectWait time.Duration `env:"RECONNECT_WAIT" default:"2s" desc:"Wait between reconnect attempts"`
 
 	// MaxReconnects is the maximum number of reconnect attempts (-1 for infinite, default: 60)
-	MaxReconnects int
+	MaxReconnects int `env:"MAX_RECONNECTS" default:"60" validate:"min=-1" desc:"Maximum reconnect attempts (-1 for infinite)"`
 
 	// Timeout is the connection timeout (default: 5s)
-	Timeout time.Duration
+	Timeout time.Duration `env:"TIMEOUT" default:"5s" desc:"Connection timeout"`
 
 	// PingInterval is the interval for PING/PONG health checks (default: 2m)
-	PingInterval time.Duration
+	PingInterval time.Duration `env:"PING_INTERVAL" default:"2m" desc:"Interval between PING health checks"`
 
 	// MaxPingsOut is the max pending pings before connection is considered stale (default: 2)
-	MaxPingsOut int
+	MaxPingsOut int `env:"MAX_PINGS_OUT" default:"2" validate:"min=1" desc:"Pending pings before the connection is stale"`
 }
 
+// EnvPrefix is the prefix of the standard NATS variables (NATS_URL, NATS_RECONNECT_WAIT, ...).
+const EnvPrefix = "NATS_"
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig(url, name string) Config {
 	return Config{
@@ -45,6 +52,30 @@ func DefaultConfig(url, name string) Config {
 	}
 }
 
+// ConfigFromEnv loads a Config from environment variables named prefix plus:
+//
+//	URL             server URL (required)
+//	NAME            default SERVICE_NAME
+//	RECONNECT_WAIT  default 2s
+//	MAX_RECONNECTS  default 60 (-1 for infinite)
+//	TIMEOUT         default 5s
+//	PING_INTERVAL   default 2m
+//	MAX_PINGS_OUT   default 2
+//
+// Use EnvPrefix for the standard names (NATS_URL, NATS_RECONNECT_WAIT, ...) and
+// a different prefix per additional connection, e.g. "EVENTS_NATS_". Every
+// missing or malformed variable is reported in a single error.
+func ConfigFromEnv(prefix string) (Config, error) {
+	var cfg Config
+	if err := config.LoadPrefixed(&cfg, prefix, config.EnvSource()); err != nil {
+		return Config{}, err
+	}
+	if cfg.Name == "" {
+		cfg.Name = config.GetEnv("SERVICE_NAME", "")
+	}
+	return cfg, nil
+}
+
 // Connect establishes a connection to a NATS server using the provided configuration.
 func Connect(cfg Config) (*nats.Conn, error) {
 	opts := []nats.Option{
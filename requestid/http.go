@@ -0,0 +1,52 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Middleware takes the request ID from the X-Request-Id header, or generates
+// one when it is missing or invalid, stores it in the request context (see
+// NewContext) and echoes it in the X-Request-Id response header.
+//
+// The ID is also stored under chi's middleware.RequestIDKey, so code using
+// middleware.GetReqID keeps working.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := ensure(r.Header.Get(Header))
+		w.Header().Set(Header, id)
+
+		ctx := NewContext(r.Context(), id)
+		ctx = context.WithValue(ctx, middleware.RequestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Transport returns an http.RoundTripper that sets the X-Request-Id header on
+// outgoing requests whose context carries a request ID. A nil base uses
+// http.DefaultTransport.
+//
+//	client := &http.Client{Transport: requestid.Transport(nil)}
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base: base}
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, id)
+	return t.base.RoundTrip(req)
+}
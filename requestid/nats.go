@@ -0,0 +1,41 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// InjectNATS sets the X-Request-Id header of msg from the context's request
+// ID, unless the header is already set.
+//
+//	msg := nats.NewMsg("devices.created")
+//	requestid.InjectNATS(ctx, msg)
+//	err := nc.PublishMsg(msg)
+func InjectNATS(ctx context.Context, msg *nats.Msg) {
+	id := FromContext(ctx)
+	if id == "" {
+		return
+	}
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	if msg.Header.Get(Header) == "" {
+		msg.Header.Set(Header, id)
+	}
+}
+
+// ExtractNATS returns a context carrying the request ID from msg's
+// X-Request-Id header, or a new one when it is missing or invalid.
+//
+//	nc.Subscribe("devices.created", func(msg *nats.Msg) {
+//		ctx := requestid.ExtractNATS(ctx, msg)
+//		logger.From(ctx).Info().Msg("device created")
+//	})
+func ExtractNATS(ctx context.Context, msg *nats.Msg) context.Context {
+	var incoming string
+	if msg.Header != nil {
+		incoming = msg.Header.Get(Header)
+	}
+	return NewContext(ctx, ensure(incoming))
+}
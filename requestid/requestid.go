@@ -0,0 +1,137 @@
+// Package requestid generates request IDs and propagates them through
+// context, logs, HTTP, gRPC and NATS, so a single ID follows a request across
+// services regardless of transport.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"github.com/nikolapavicevic-001/CommonGo/logger"
+)
+
+// Header is the HTTP and NATS header carrying the request ID.
+const Header = "X-Request-Id"
+
+// MetadataKey is the gRPC metadata key carrying the request ID.
+const MetadataKey = "x-request-id"
+
+// MaxLength is the longest incoming request ID accepted; longer or
+// non-printable IDs are replaced with a generated one.
+const MaxLength = 128
+
+// Generator returns a new request ID.
+type Generator func() string
+
+var generator atomic.Pointer[Generator]
+
+// SetGenerator replaces the generator used by New (default: UUIDv7).
+// It is meant to be called once at startup.
+func SetGenerator(g Generator) {
+	generator.Store(&g)
+}
+
+// New returns a new request ID from the configured generator.
+func New() string {
+	if g := generator.Load(); g != nil {
+		return (*g)()
+	}
+	return UUIDv7()
+}
+
+// UUIDv7 returns a time-ordered RFC 9562 version 7 UUID,
+// e.g. 01928f6e-5c3a-7b2e-9f1d-3a4b5c6d7e8f.
+func UUIDv7() string {
+	var b [16]byte
+	putTimestamp(b[:6])
+	_, _ = rand.Read(b[6:])
+	b[6] = b[6]&0x0f | 0x70 // version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a lexicographically sortable ULID (26 Crockford base32
+// characters), e.g. 01JAHX3M5Q8ZKCN7WS2Y6F4D9B.
+func ULID() string {
+	var b [16]byte
+	putTimestamp(b[:6])
+	_, _ = rand.Read(b[6:])
+
+	// 26 characters encode 130 bits: the 128-bit value with two leading zero bits.
+	bit := func(i int) byte {
+		if i < 0 {
+			return 0
+		}
+		return b[i/8] >> (7 - i%8) & 1
+	}
+	var out [26]byte
+	for i := range out {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v = v<<1 | bit(i*5+j-2)
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
+
+// putTimestamp writes the current Unix time in milliseconds as 48 big-endian bits.
+func putTimestamp(dst []byte) {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(dst, ts[2:])
+}
+
+// Valid reports whether an incoming request ID is acceptable: 1 to MaxLength
+// printable ASCII characters without spaces, so it is safe to log and echo.
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+type ctxKey struct{}
+
+// NewContext returns a context carrying id, with request_id added to the
+// context logger (see logger.From).
+func NewContext(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, ctxKey{}, id)
+	return logger.WithRequestID(ctx, id)
+}
+
+// FromContext returns the request ID stored by NewContext, or "".
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// ensure returns the valid incoming ID or a new one.
+func ensure(incoming string) string {
+	if Valid(incoming) {
+		return incoming
+	}
+	return New()
+}